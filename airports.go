@@ -0,0 +1,71 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"anyhol/itinerary"
+)
+
+// runAirports runs the airports commands. "airports search" finds the tokens
+// of airports by name, city, country or code, so nobody has to grep the CSV.
+func runAirports(args []string) {
+	fs := flag.NewFlagSet("airports search", flag.ExitOnError)
+	addCommonFlags(fs)
+	jsonFlag := fs.Bool("json", false, "Print the matches as JSON")
+	limitFlag := fs.Int("limit", 10, "Most matches printed, 0 prints all")
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "\033[32mUsage:\033[0m \033[34mgo run . airports search \033[33m[-json] [-limit 10]\033[0m \033[34m[QUERY] [LOOKUP FILE]\033[0m\n")
+		fs.PrintDefaults()
+	}
+	if len(args) == 0 || args[0] != "search" {
+		fs.Usage()
+		os.Exit(2)
+	}
+	fs.Parse(args[1:])
+
+	if fs.NArg() != 2 {
+		fs.Usage()
+		os.Exit(2)
+	}
+	query, lookupPath := fs.Arg(0), fs.Arg(1)
+
+	columns, err := lookupColumns(configFlag, columnFlags, aliasFlags)
+	if err != nil {
+		exitWithError("Error loading airport lookup:", err)
+	}
+	lookup, err := itinerary.LoadLookups(columns, lookupPath, lookupFlags...) // loading lookup with overlays
+	if err != nil {
+		exitWithError("Error loading airport lookup:", err)
+	}
+
+	matches := itinerary.Search(lookup, query, *limitFlag)
+	if len(matches) == 0 {
+		exitWithError("Search failed:", fmt.Errorf("no airports match %q", query))
+	}
+
+	if *jsonFlag {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(matches); err != nil {
+			exitWithError("Error:", err)
+		}
+		return
+	}
+	printMatches(matches)
+}
+
+// printMatches prints matches as a table of their tokens and fields
+func printMatches(matches []itinerary.Match) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "TOKENS\tNAME\tCITY\tCOUNTRY")
+	for _, m := range matches {
+		a := m.Airport
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", strings.Join(m.Tokens, " "), a.Name, a.Municipality, a.Country)
+	}
+	w.Flush()
+}
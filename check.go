@@ -0,0 +1,60 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"anyhol/itinerary"
+)
+
+// runCheck lints an itinerary without writing any output. It exits with
+// an error when problems are found, so it can gate commits.
+func runCheck(args []string) {
+	fs := flag.NewFlagSet("check", flag.ExitOnError)
+	addCommonFlags(fs)
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "\033[32mUsage:\033[0m \033[34mgo run . check \033[33m[-b bonus]\033[0m \033[34m[INPUT FILE] [LOOKUP FILE]\033[0m\n")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+	if err := resolveMode(); err != nil {
+		exitWithError("Error:", err)
+	}
+
+	if fs.NArg() != 2 {
+		fs.Usage()
+		os.Exit(2)
+	}
+	inputPath, lookupPath := fs.Arg(0), fs.Arg(1)
+
+	inputFile, err := os.Open(inputPath) // Opening input
+	if err != nil {
+		exitWithError("Error checking itinerary:", fmt.Errorf("%w: %w", itinerary.ErrInputNotFound, err))
+	}
+	defer inputFile.Close()
+
+	columns, err := lookupColumns(configFlag, columnFlags, aliasFlags)
+	if err != nil {
+		exitWithError("Error loading airport lookup:", err)
+	}
+	lookup, err := itinerary.LoadLookups(columns, lookupPath, lookupFlags...) // loading lookup with overlays
+	if err != nil {
+		exitWithError("Error loading airport lookup:", err)
+	}
+
+	problems, err := itinerary.New(lookup, itinerary.Options{Extended: bonusFlag}).Check(inputFile)
+	if err != nil {
+		exitWithError("Error checking itinerary:", err)
+	}
+
+	for _, problem := range problems {
+		fmt.Printf("%s:%s\n", inputPath, problem)
+	}
+	if len(problems) > 0 {
+		inputFile.Close()
+		exitWithError("Check failed:", fmt.Errorf("%d problems found", len(problems)))
+	}
+
+	println("\033[32mNo problems found.\033[0m")
+}
@@ -0,0 +1,42 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"anyhol/itinerary"
+)
+
+// runLookup runs the lookup commands. "lookup compile" writes the index of
+// a lookup, so later runs load it without parsing the CSV.
+func runLookup(args []string) {
+	fs := flag.NewFlagSet("lookup compile", flag.ExitOnError)
+	addCommonFlags(fs)
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "\033[32mUsage:\033[0m \033[34mgo run . lookup compile \033[33m[-column field=header] [-alias field=header]\033[0m \033[34m[LOOKUP FILE]\033[0m\n")
+		fs.PrintDefaults()
+	}
+	if len(args) == 0 || args[0] != "compile" {
+		fs.Usage()
+		os.Exit(2)
+	}
+	fs.Parse(args[1:])
+
+	if fs.NArg() != 1 {
+		fs.Usage()
+		os.Exit(2)
+	}
+	lookupPath := fs.Arg(0)
+
+	columns, err := lookupColumns(configFlag, columnFlags, aliasFlags)
+	if err != nil {
+		exitWithError("Error loading airport lookup:", err)
+	}
+	lookup, err := itinerary.CompileLookup(lookupPath, columns)
+	if err != nil {
+		exitWithError("Error compiling airport lookup:", err)
+	}
+
+	fmt.Printf("\033[32mCompiled %d airports into %s\033[0m\n", len(lookup.Airports()), itinerary.IndexPath(lookupPath))
+}
@@ -0,0 +1,90 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"anyhol/itinerary"
+)
+
+// config is the optional JSON file given with -config.
+//
+//	{
+//	  "columns": {"iata_code": "iata"},
+//	  "aliases": {"name": ["airport_name", "airport"]}
+//	}
+type config struct {
+	Columns map[string]string   `json:"columns"` // field = the only header name
+	Aliases map[string][]string `json:"aliases"` // field = extra header names
+}
+
+// columnFlag collects repeated "field=header" flags
+type columnFlag []string
+
+func (c *columnFlag) String() string {
+	return strings.Join(*c, ",")
+}
+
+func (c *columnFlag) Set(value string) error {
+	if !strings.Contains(value, "=") {
+		return fmt.Errorf("want field=header, got %q", value)
+	}
+	*c = append(*c, value)
+	return nil
+}
+
+// fileFlag collects repeated file flags
+type fileFlag []string
+
+func (f *fileFlag) String() string {
+	return strings.Join(*f, ",")
+}
+
+func (f *fileFlag) Set(value string) error {
+	*f = append(*f, value)
+	return nil
+}
+
+// lookupColumns builds the column mapping from the config file and flags.
+// Flags are applied after the config file, so they win.
+func lookupColumns(configPath string, overrides, aliases columnFlag) (itinerary.Columns, error) {
+	columns := itinerary.DefaultColumns()
+
+	if configPath != "" {
+		data, err := os.ReadFile(configPath)
+		if err != nil {
+			return nil, fmt.Errorf("config not found: %w", err)
+		}
+		var conf config
+		if err := json.Unmarshal(data, &conf); err != nil {
+			return nil, fmt.Errorf("malformed config %s: %w", configPath, err)
+		}
+		for field, names := range conf.Aliases {
+			if err := columns.Alias(field, names...); err != nil {
+				return nil, err
+			}
+		}
+		for field, name := range conf.Columns {
+			if err := columns.Override(field, name); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	for _, alias := range aliases {
+		field, name, _ := strings.Cut(alias, "=")
+		if err := columns.Alias(field, name); err != nil {
+			return nil, err
+		}
+	}
+	for _, override := range overrides {
+		field, name, _ := strings.Cut(override, "=")
+		if err := columns.Override(field, name); err != nil {
+			return nil, err
+		}
+	}
+
+	return columns, nil
+}
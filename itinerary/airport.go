@@ -0,0 +1,145 @@
+package itinerary
+
+import "sort"
+
+// Airport is a single record of the airport lookup.
+type Airport struct {
+	Name         string            `json:"name"`
+	Country      string            `json:"iso_country"` // ISO 3166 country code
+	Municipality string            `json:"municipality"`
+	ICAO         string            `json:"icao_code"`
+	IATA         string            `json:"iata_code"`
+	Latitude     float64           `json:"latitude"`
+	Longitude    float64           `json:"longitude"`
+	Timezone     string            `json:"tz,omitempty"`    // IANA time zone, e.g. "Europe/Helsinki"
+	Extra        map[string]string `json:"extra,omitempty"` // other lookup columns by header name, not kept by the index
+
+	Source    string            `json:"source,omitempty"`    // lookup file the airport comes from
+	Overrides map[string]string `json:"overrides,omitempty"` // overlay files of changed fields by field
+}
+
+// SourceOf returns the lookup file the value of field comes from.
+func (a *Airport) SourceOf(field string) string {
+	if source, ok := a.Overrides[field]; ok {
+		return source
+	}
+	return a.Source
+}
+
+// overridden records source as the file of every field changed since before
+func (a *Airport) overridden(before *Airport, source string) {
+	changed := map[string]bool{
+		FieldName:         a.Name != before.Name,
+		FieldCountry:      a.Country != before.Country,
+		FieldMunicipality: a.Municipality != before.Municipality,
+		FieldICAO:         a.ICAO != before.ICAO,
+		FieldIATA:         a.IATA != before.IATA,
+		FieldCoordinates:  a.Latitude != before.Latitude || a.Longitude != before.Longitude,
+		FieldTimezone:     a.Timezone != before.Timezone,
+	}
+	overrides := make(map[string]string, len(a.Overrides)+1)
+	for field, file := range a.Overrides {
+		overrides[field] = file
+	}
+	for field, ok := range changed {
+		if ok {
+			overrides[field] = source
+		}
+	}
+	if len(overrides) > 0 {
+		a.Overrides = overrides
+	}
+}
+
+// Lookup finds airports by their codes. Alternative lookup backends only
+// have to implement these two methods.
+type Lookup interface {
+	ByIATA(code string) (*Airport, bool)
+	ByICAO(code string) (*Airport, bool)
+}
+
+// MapLookup is a Lookup held in memory.
+type MapLookup struct {
+	iata map[string]*Airport
+	icao map[string]*Airport
+
+	index *compiledIndex // airports of a compiled lookup until it is changed, see load
+}
+
+// NewMapLookup returns a MapLookup holding airports.
+func NewMapLookup(airports ...*Airport) *MapLookup {
+	m := &MapLookup{iata: make(map[string]*Airport), icao: make(map[string]*Airport)}
+	for _, a := range airports {
+		m.Add(a)
+	}
+	return m
+}
+
+// Add stores a, replacing airports with the same codes.
+func (m *MapLookup) Add(a *Airport) {
+	m.load()
+	if a.IATA != "" {
+		m.iata[a.IATA] = a
+	}
+	if a.ICAO != "" {
+		m.icao[a.ICAO] = a
+	}
+}
+
+// Remove deletes a from the lookup.
+func (m *MapLookup) Remove(a *Airport) {
+	m.load()
+	if m.iata[a.IATA] == a {
+		delete(m.iata, a.IATA)
+	}
+	if m.icao[a.ICAO] == a {
+		delete(m.icao, a.ICAO)
+	}
+}
+
+// Airports returns every airport of the lookup, ordered by IATA and ICAO
+// codes.
+func (m *MapLookup) Airports() []*Airport {
+	if x := m.index; x != nil { // compiled in this order
+		airports := make([]*Airport, len(x.airports))
+		for i := range airports {
+			airports[i] = x.airport(i)
+		}
+		return airports
+	}
+	seen := make(map[*Airport]bool, len(m.iata))
+	var airports []*Airport
+	for _, codes := range []map[string]*Airport{m.iata, m.icao} {
+		for _, a := range codes {
+			if !seen[a] {
+				seen[a] = true
+				airports = append(airports, a)
+			}
+		}
+	}
+	sort.Slice(airports, func(i, j int) bool {
+		if airports[i].IATA != airports[j].IATA {
+			return airports[i].IATA < airports[j].IATA
+		}
+		return airports[i].ICAO < airports[j].ICAO
+	})
+	return airports
+}
+
+// ByIATA finds an airport by its three letter IATA code.
+func (m *MapLookup) ByIATA(code string) (*Airport, bool) {
+	if m.index != nil {
+		return m.index.find(m.index.iata, code, false)
+	}
+	a, ok := m.iata[code]
+	return a, ok
+}
+
+// ByICAO finds an airport by its four letter ICAO code.
+func (m *MapLookup) ByICAO(code string) (*Airport, bool) {
+	if m.index != nil {
+		return m.index.find(m.index.icao, code, true)
+	}
+	a, ok := m.icao[code]
+	return a, ok
+}
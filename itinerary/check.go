@@ -0,0 +1,130 @@
+package itinerary
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Problem is something in an itinerary that won't convert as intended.
+type Problem struct {
+	Line    int    // line number starting from 1
+	Column  int    // column in characters starting from 1
+	Token   string // text the problem is about
+	Message string
+
+	Suggestions []string // known codes close to an unresolved code
+}
+
+func (p Problem) String() string {
+	return fmt.Sprintf("%d:%d: %s", p.Line, p.Column, p.Message)
+}
+
+// Check reads the itinerary from r and reports every code that is not in the
+// lookup, every date or time that can't be parsed and every suspicious
+// control character. Nothing is converted.
+func (p *Prettifier) Check(r io.Reader) ([]Problem, error) {
+	var problems []Problem
+	reader := bufio.NewReader(r)
+
+	for n := 1; ; n++ {
+		line, err := reader.ReadString('\n')
+		if err != nil && !errors.Is(err, io.EOF) {
+			return problems, err
+		}
+		if line == "" && err != nil { // input is over
+			break
+		}
+
+		line = strings.TrimSuffix(line, "\n")
+		line = strings.TrimSuffix(line, "\r") // \r\n line endings
+		problems = append(problems, p.checkLine(line, n)...)
+
+		if err != nil {
+			break
+		}
+	}
+
+	return problems, nil
+}
+
+// checkLine finds the problems of a single line
+func (p *Prettifier) checkLine(line string, n int) []Problem {
+	var problems []Problem
+	column := func(offset int) int {
+		return utf8.RuneCountInString(line[:offset]) + 1
+	}
+
+	for offset, r := range line {
+		if isSuspicious(r) {
+			problems = append(problems, Problem{
+				Line:    n,
+				Column:  column(offset),
+				Token:   string(r),
+				Message: fmt.Sprintf("suspicious control character %U", r),
+			})
+		}
+	}
+
+	tokens := scanTokens(line, p.opts.Extended)
+	var resolved []*Airport // airports of the line, for suggestions
+	for _, tok := range tokens {
+		if tok.kind == tokenAirport || tok.kind == tokenCity || tok.kind == tokenCountry {
+			if airport, ok := tok.airport(p.lookup); ok {
+				resolved = append(resolved, airport)
+			}
+		}
+	}
+
+	for _, tok := range tokens {
+		var message string
+		var suggestions []string
+		switch tok.kind {
+		case tokenAirport, tokenCity, tokenCountry:
+			if _, ok := tok.airport(p.lookup); !ok {
+				message = fmt.Sprintf("unresolved airport code %s", tok.text)
+				if suggestions = p.suggest(tok, resolved); suggestions != nil {
+					message += ", " + didYouMean(suggestions)
+				}
+			}
+		case tokenDate, tokenTime12, tokenTime24:
+			if _, err := parseISOTime(tok.arg); err != nil {
+				message = fmt.Sprintf("malformed %s %s", tok.kind, tok.text)
+			}
+		case tokenLocal12, tokenLocal24:
+			if _, err := p.localTime(tok); err != nil {
+				message = fmt.Sprintf("can't convert %s: %s", tok.text, err)
+			}
+		case tokenDistance:
+			if _, err := p.distance(tok); err != nil {
+				message = fmt.Sprintf("can't convert %s: %s", tok.text, err)
+			}
+		case tokenDuration:
+			if _, err := duration(tok); err != nil {
+				message = fmt.Sprintf("can't convert %s: %s", tok.text, err)
+			}
+		}
+		if message != "" {
+			problems = append(problems, Problem{Line: n, Column: column(tok.start), Token: tok.text, Message: message, Suggestions: suggestions})
+		}
+	}
+
+	sort.SliceStable(problems, func(i, j int) bool { // by column
+		return problems[i].Column < problems[j].Column
+	})
+	return problems
+}
+
+// isSuspicious reports control and invisible formatting characters. Tabs and
+// the line breaks \v \f \r are expected in itineraries.
+func isSuspicious(r rune) bool {
+	if strings.ContainsRune("\t\v\f\r", r) {
+		return false
+	}
+	return unicode.IsControl(r) || unicode.Is(unicode.Cf, r) || r == utf8.RuneError
+}
@@ -0,0 +1,113 @@
+package itinerary
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Lookup fields. Each of them has to be found in the lookup header, except
+// the optional ones.
+const (
+	FieldName         = "name"
+	FieldCountry      = "iso_country"
+	FieldMunicipality = "municipality"
+	FieldICAO         = "icao_code"
+	FieldIATA         = "iata_code"
+	FieldCoordinates  = "coordinates"
+	FieldTimezone     = "tz" // IANA time zone, optional
+)
+
+// Fields lists the lookup fields in the order of airport-lookup.csv.
+var Fields = []string{FieldName, FieldCountry, FieldMunicipality, FieldICAO, FieldIATA, FieldCoordinates}
+
+// OptionalFields lists the lookup fields that may be missing or blank.
+var OptionalFields = []string{FieldTimezone}
+
+// FieldDelete is the column of overlay lookups that removes an airport when
+// it holds "true", "yes" or "1".
+const FieldDelete = "delete"
+
+// Columns maps every lookup field to the header names it may appear under.
+// Header names are matched case-insensitively.
+type Columns map[string][]string
+
+// DefaultColumns returns a mapping where every field is found under its own name.
+func DefaultColumns() Columns {
+	cols := make(Columns, len(Fields)+len(OptionalFields)+1)
+	for _, field := range overlayFields() {
+		cols[field] = []string{field}
+	}
+	return cols
+}
+
+// Alias adds header names that field may appear under.
+func (c Columns) Alias(field string, names ...string) error {
+	if !isField(field) {
+		return fmt.Errorf("%w %q", ErrUnknownField, field)
+	}
+	c[field] = append(c[field], names...)
+	return nil
+}
+
+// Override makes field be found only under the header name.
+func (c Columns) Override(field, name string) error {
+	if !isField(field) {
+		return fmt.Errorf("%w %q", ErrUnknownField, field)
+	}
+	c[field] = []string{name}
+	return nil
+}
+
+// resolve finds the position of every field in the header row. Fields of
+// required must be found.
+func (c Columns) resolve(header, fields, required []string) (map[string]int, error) {
+	positions := make(map[string]int, len(fields))
+	for _, field := range fields {
+		for i, column := range header {
+			if !c.matches(field, column) {
+				continue
+			}
+			if _, found := positions[field]; found {
+				return nil, &MalformedLookupError{Row: 1, Column: i + 1, Field: field, Reason: "duplicated column"}
+			}
+			positions[field] = i
+		}
+		if _, found := positions[field]; !found && contains(required, field) {
+			return nil, &MalformedLookupError{Row: 1, Field: field, Reason: "missing column"}
+		}
+	}
+	return positions, nil
+}
+
+// matches reports whether a header column holds field
+func (c Columns) matches(field, column string) bool {
+	column = strings.TrimSpace(column)
+	for _, name := range c[field] {
+		if strings.EqualFold(column, strings.TrimSpace(name)) {
+			return true
+		}
+	}
+	return false
+}
+
+func allFields() []string {
+	return append(append([]string(nil), Fields...), OptionalFields...)
+}
+
+// overlayFields are the fields of overlay lookups
+func overlayFields() []string {
+	return append(allFields(), FieldDelete)
+}
+
+func isField(field string) bool {
+	return contains(overlayFields(), field)
+}
+
+func contains(fields []string, field string) bool {
+	for _, f := range fields {
+		if f == field {
+			return true
+		}
+	}
+	return false
+}
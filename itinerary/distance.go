@@ -0,0 +1,64 @@
+package itinerary
+
+import (
+	"fmt"
+	"math"
+	"strings"
+)
+
+// DistanceUnit is the unit distances are written in.
+type DistanceUnit string
+
+const (
+	Kilometers    DistanceUnit = "km"
+	Miles         DistanceUnit = "mi"
+	NauticalMiles DistanceUnit = "nm"
+)
+
+// kilometers in a unit
+var unitLengths = map[DistanceUnit]float64{
+	Kilometers:    1,
+	Miles:         1.609344,
+	NauticalMiles: 1.852,
+}
+
+// Valid reports whether u is a known unit.
+func (u DistanceUnit) Valid() bool {
+	_, ok := unitLengths[u]
+	return ok
+}
+
+// mean radius of the Earth in kilometers
+const earthRadius = 6371.0088
+
+// distance computes the great-circle distance in kilometers between the
+// airports of a DIST token, e.g. DIST(#HIR, #AHJ)
+func (p *Prettifier) distance(tok token) (float64, error) {
+	from, to, found := strings.Cut(tok.arg, ",")
+	if !found {
+		return 0, fmt.Errorf("want DIST(#IATA, #IATA)")
+	}
+	a, err := p.airportRef(strings.TrimSpace(from))
+	if err != nil {
+		return 0, err
+	}
+	b, err := p.airportRef(strings.TrimSpace(to))
+	if err != nil {
+		return 0, err
+	}
+	return greatCircle(a.Latitude, a.Longitude, b.Latitude, b.Longitude), nil
+}
+
+// greatCircle is the haversine distance in kilometers between two points
+func greatCircle(lat1, lon1, lat2, lon2 float64) float64 {
+	rad := math.Pi / 180
+	dLat := (lat2 - lat1) * rad
+	dLon := (lon2 - lon1) * rad
+	h := math.Sin(dLat/2)*math.Sin(dLat/2) + math.Cos(lat1*rad)*math.Cos(lat2*rad)*math.Sin(dLon/2)*math.Sin(dLon/2)
+	return 2 * earthRadius * math.Asin(math.Sqrt(math.Min(h, 1)))
+}
+
+// formatDistance writes a distance in kilometers in unit, e.g. "1234 km"
+func formatDistance(km float64, unit DistanceUnit) string {
+	return fmt.Sprintf("%.0f %s", km/unitLengths[unit], unit)
+}
@@ -0,0 +1,44 @@
+package itinerary
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// duration computes the time between the two timestamps of a DUR token,
+// e.g. DUR(2024-07-23T15:29-11:00, 2024-07-24T06:10+02:00)
+func duration(tok token) (time.Duration, error) {
+	from, to, found := strings.Cut(tok.arg, ",")
+	if !found {
+		return 0, errors.New("want DUR(departure, arrival)")
+	}
+
+	start, err := parseISOTime(strings.TrimSpace(from))
+	if err != nil {
+		return 0, fmt.Errorf("malformed time %s", strings.TrimSpace(from))
+	}
+	end, err := parseISOTime(strings.TrimSpace(to))
+	if err != nil {
+		return 0, fmt.Errorf("malformed time %s", strings.TrimSpace(to))
+	}
+
+	d := end.Sub(start) // offsets are part of the parsed times
+	if d < 0 {
+		return 0, errors.New("negative duration")
+	}
+	return d, nil
+}
+
+// formatDuration writes a duration as "14h 41m"
+func formatDuration(d time.Duration) string {
+	minutes := int64(d / time.Minute)
+	return fmt.Sprintf("%dh %dm", minutes/60, minutes%60)
+}
+
+// isoDuration writes a duration the ISO 8601 way, e.g. "PT14H41M"
+func isoDuration(d time.Duration) string {
+	minutes := int64(d / time.Minute)
+	return fmt.Sprintf("PT%dH%dM", minutes/60, minutes%60)
+}
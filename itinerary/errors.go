@@ -0,0 +1,58 @@
+package itinerary
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Errors returned by the package. They are wrapped with more details, so
+// compare them with errors.Is.
+var (
+	ErrLookupNotFound = errors.New("lookup not found")
+	ErrInputNotFound  = errors.New("input not found")
+	ErrSameFile       = errors.New("output is the same file as an input")
+	ErrUnknownField   = errors.New("unknown lookup field")
+	ErrMalformedInput = errors.New("malformed structured itinerary")
+)
+
+// MalformedLookupError describes a lookup record that can't be used.
+type MalformedLookupError struct {
+	File   string // empty when the lookup is not read from a file
+	Row    int    // record number, the header is row 1
+	Line   int    // line of the file where the record starts
+	Column int    // column number starting from 1, 0 when the whole row is wrong
+	Field  string // lookup field of the column, if known
+	Reason string
+	Err    error // underlying error, if any
+}
+
+func (e *MalformedLookupError) Error() string {
+	msg := "malformed airport lookup"
+	if e.File != "" {
+		msg += " " + e.File
+	}
+	if e.Line > 0 {
+		msg += fmt.Sprintf(":%d", e.Line)
+	}
+	if e.Row > 0 {
+		msg += fmt.Sprintf(" (row %d", e.Row)
+		if e.Column > 0 {
+			msg += fmt.Sprintf(", column %d", e.Column)
+		}
+		msg += ")"
+	}
+	if e.Field != "" {
+		msg += fmt.Sprintf(": %s", e.Field)
+	}
+	if e.Reason != "" {
+		msg += ": " + e.Reason
+	}
+	if e.Err != nil {
+		msg += ": " + e.Err.Error()
+	}
+	return msg
+}
+
+func (e *MalformedLookupError) Unwrap() error {
+	return e.Err
+}
@@ -0,0 +1,85 @@
+package itinerary
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+// ConvertFile converts the itinerary at inputPath into outputPath. Nothing
+// is written to outputPath unless the whole conversion succeeds. The same
+// conversion is also written to the extra targets, e.g. a terminal preview.
+func (p *Prettifier) ConvertFile(inputPath, outputPath string, extra ...Target) error {
+	inputFile, err := os.Open(inputPath) // Opening input
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrInputNotFound, err)
+	}
+	defer inputFile.Close()
+
+	if err := CheckDistinct(outputPath, inputPath); err != nil {
+		return err
+	}
+
+	return WriteFileAtomic(outputPath, func(w io.Writer) error {
+		return p.ConvertTo(inputFile, append([]Target{{W: w, Renderer: p.opts.Renderer}}, extra...)...)
+	})
+}
+
+// CheckDistinct returns an error when outputPath is the same file as one of
+// the paths, even through links.
+func CheckDistinct(outputPath string, paths ...string) error {
+	output, err := os.Stat(outputPath)
+	if err != nil {
+		return nil // output does not exist yet
+	}
+	for _, path := range paths {
+		if info, err := os.Stat(path); err == nil && os.SameFile(output, info) {
+			return fmt.Errorf("%w: %s", ErrSameFile, path)
+		}
+	}
+	return nil
+}
+
+// WriteFileAtomic calls write with a temporary file in the directory of path
+// and renames it over path only when write succeeds. Missing directories
+// are created and the permissions of an existing file are kept.
+func WriteFileAtomic(path string, write func(w io.Writer) error) (err error) {
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return fmt.Errorf("creating output: %w", err)
+	}
+
+	temp, err := os.CreateTemp(dir, "."+filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("creating output: %w", err)
+	}
+	defer func() {
+		if err != nil { // leaving no temporary file behind
+			temp.Close()
+			os.Remove(temp.Name())
+		}
+	}()
+
+	mode := os.FileMode(0o644)
+	if info, err := os.Stat(path); err == nil {
+		mode = info.Mode().Perm() // overwriting keeps permissions
+	}
+	if err := temp.Chmod(mode); err != nil {
+		return fmt.Errorf("creating output: %w", err)
+	}
+
+	if err := write(temp); err != nil {
+		return err
+	}
+	if err := temp.Sync(); err != nil {
+		return fmt.Errorf("writing output: %w", err)
+	}
+	if err := temp.Close(); err != nil {
+		return fmt.Errorf("writing output: %w", err)
+	}
+	if err := os.Rename(temp.Name(), path); err != nil {
+		return fmt.Errorf("writing output: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,229 @@
+package itinerary
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// segmentKind tells what a piece of a converted line holds
+type segmentKind int
+
+const (
+	segmentText segmentKind = iota
+	segmentAirport
+	segmentCity
+	segmentDate
+	segmentTime
+	segmentDuration
+	segmentCountry
+	segmentDistance
+)
+
+// segment is a piece of a converted line. Lines are converted into
+// segments once and every output renders them in its own way.
+type segment struct {
+	kind     segmentKind
+	text     string // text, or the formatted date, time, duration, country or distance
+	airport  *Airport
+	time     time.Time
+	duration time.Duration
+	distance float64 // in kilometers
+	problem  string  // why a token is flagged instead of converted
+	token    *token  // token the segment is converted from, nil for text between tokens
+}
+
+func (s segment) render(r Renderer) string {
+	switch s.kind {
+	case segmentAirport:
+		return r.AirportName(s.airport)
+	case segmentCity:
+		return r.City(s.airport)
+	case segmentDate:
+		return r.Date(s.time, s.text)
+	case segmentTime:
+		return r.Time(s.time, s.text)
+	case segmentDuration:
+		if d, ok := r.(DurationRenderer); ok {
+			return d.Duration(s.duration, s.text)
+		}
+	case segmentCountry:
+		if c, ok := r.(CountryRenderer); ok {
+			return c.Country(s.airport, s.text)
+		}
+	case segmentDistance:
+		if d, ok := r.(DistanceRenderer); ok {
+			return d.Distance(s.distance, s.text)
+		}
+	}
+	return r.Text(s.text)
+}
+
+// renderSegments renders a converted line
+func renderSegments(segments []segment, r Renderer) string {
+	var result strings.Builder
+	for _, s := range segments {
+		result.WriteString(s.render(r))
+	}
+	return result.String()
+}
+
+// Converting #, ##, * and dates with times
+func (p *Prettifier) processLine(line string) []segment {
+	var segments []segment
+	last := 0
+	for _, tok := range scanTokens(line, p.opts.Extended) {
+		if last < tok.start {
+			segments = append(segments, segment{kind: segmentText, text: line[last:tok.start]}) // text between tokens
+		}
+		tok := tok
+		s := p.convertToken(tok)
+		s.token = &tok
+		segments = append(segments, s)
+		last = tok.end
+	}
+	if last < len(line) {
+		segments = append(segments, segment{kind: segmentText, text: line[last:]})
+	}
+	if p.opts.DayOffsets {
+		markDayOffsets(segments)
+	}
+	return segments
+}
+
+// longest time between a departure and its arrival, times further apart
+// are not a flight
+const maxFlight = 48 * time.Hour
+
+// markDayOffsets pairs the times of a line in order, every departure with
+// the time right after it, its arrival. Arrivals on another calendar date
+// than their departure get the difference in days, e.g. "06:10 (+02:00) +1".
+// Dates are compared as written, each in its own offset. Pairs that end
+// before they start or last longer than maxFlight are unrelated times and
+// are left alone.
+func markDayOffsets(segments []segment) {
+	var departure *segment
+	for i := range segments {
+		if segments[i].kind != segmentTime {
+			continue
+		}
+		if departure == nil {
+			departure = &segments[i]
+			continue
+		}
+		arrival := &segments[i]
+		if flight := arrival.time.Sub(departure.time); flight >= 0 && flight <= maxFlight {
+			if days := calendarDays(departure.time, arrival.time); days != 0 {
+				arrival.text += fmt.Sprintf(" %+d", days)
+			}
+		}
+		departure = nil // the next time departs again
+	}
+}
+
+// calendarDays counts the calendar days from the date of a to the date of b
+func calendarDays(a, b time.Time) int {
+	dateA := time.Date(a.Year(), a.Month(), a.Day(), 0, 0, 0, 0, time.UTC)
+	dateB := time.Date(b.Year(), b.Month(), b.Day(), 0, 0, 0, 0, time.UTC)
+	return int(dateB.Sub(dateA).Hours() / 24)
+}
+
+// convertToken returns the human readable form of a token
+func (p *Prettifier) convertToken(tok token) segment {
+	switch tok.kind {
+	case tokenAirport: // if # or ##
+		if airport, exists := tok.airport(p.lookup); exists { // returning name of airport
+			return segment{kind: segmentAirport, airport: airport}
+		}
+
+	case tokenCity: // if *# or *##
+		if airport, exists := tok.airport(p.lookup); exists { // returning city (municipality)
+			return segment{kind: segmentCity, airport: airport}
+		}
+
+	case tokenCountry: // if ^# or ^##
+		if airport, exists := tok.airport(p.lookup); exists { // returning country
+			return segment{kind: segmentCountry, text: p.country(airport.Country), airport: airport}
+		}
+
+	case tokenDate: // if D(Date)
+		if t, date, err := formatISODate(tok.arg, p.opts.Locale); err == nil { // converting Date from D(YYYY-MM-DDTHH:mmZ) to human readable
+			return segment{kind: segmentDate, text: date, time: t}
+		}
+
+	case tokenTime12: // if T12(Time)
+		if t, formatted, err := formatISOTime(tok.arg, true, p.opts.Locale); err == nil { // converting Time from T12(YYYY-MM-DDTHH:mmZ) to human readable
+			return segment{kind: segmentTime, text: formatted, time: t}
+		}
+
+	case tokenTime24: // if T24
+		if t, formatted, err := formatISOTime(tok.arg, false, p.opts.Locale); err == nil { // converting Time from T24(YYYY-MM-DDTHH:mmZ) to human readable
+			return segment{kind: segmentTime, text: formatted, time: t}
+		}
+
+	case tokenLocal12, tokenLocal24: // if L12 or L24
+		if t, err := p.localTime(tok); err == nil { // converting to the time zone of the airport
+			return segment{kind: segmentTime, text: formatTime(t, tok.kind == tokenLocal12, p.opts.Locale), time: t}
+		}
+
+	case tokenDistance: // if DIST
+		if km, err := p.distance(tok); err == nil { // great-circle distance between the airports
+			return segment{kind: segmentDistance, text: formatDistance(km, p.opts.Unit), distance: km}
+		}
+
+	case tokenDuration: // if DUR
+		d, err := duration(tok)
+		if err != nil { // flagged, not left as it is
+			return segment{kind: segmentText, text: tok.text + " [" + err.Error() + "]", problem: err.Error()}
+		}
+		return segment{kind: segmentDuration, text: formatDuration(d), duration: d}
+	}
+
+	return segment{kind: segmentText, text: tok.text} // unknown codes and malformed dates stay as they are
+}
+
+// parseISOTime parses the timestamps of itineraries,
+// "2006-01-02T15:04Z" and "2006-01-02T15:04-07:00"
+func parseISOTime(iso string) (time.Time, error) {
+	if strings.HasSuffix(iso, "Z") { // if "Z"
+		return time.Parse("2006-01-02T15:04Z", iso)
+	}
+	return time.Parse("2006-01-02T15:04-07:00", iso) // if "02:00" format
+}
+
+// Formatting Date
+func formatISODate(isoDate string, locale *Locale) (time.Time, string, error) {
+	parsedTime, err := parseISOTime(isoDate)
+	if err != nil {
+		return time.Time{}, "", err
+	}
+
+	return parsedTime, locale.formatDate(parsedTime), nil
+}
+
+// Formatting Time
+func formatISOTime(isoTime string, is12HourFormat bool, locale *Locale) (time.Time, string, error) {
+	t, err := parseISOTime(isoTime)
+	if err != nil {
+		return time.Time{}, "", err
+	}
+
+	return t, formatTime(t, is12HourFormat, locale), nil
+}
+
+// formatTime writes a time with its offset, "Z" is written as "+00:00"
+func formatTime(t time.Time, is12HourFormat bool, locale *Locale) string {
+	var formattedTime string
+	offset := t.Format("(-07:00)")
+
+	if is12HourFormat && !locale.Hour24 {
+		formattedTime = t.Format("03:04") + locale.AM // if 12H format
+		if t.Hour() >= 12 {
+			formattedTime = t.Format("03:04") + locale.PM
+		}
+	} else {
+		formattedTime = t.Format("15:04") // if 24H format
+	}
+
+	return fmt.Sprintf("%s %s", formattedTime, offset) // printing human readable
+}
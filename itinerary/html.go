@@ -0,0 +1,51 @@
+package itinerary
+
+import (
+	"fmt"
+	"html"
+	"time"
+)
+
+// HTMLRenderer writes converted text as an HTML fragment for emails and web
+// pages. Text is escaped, converted values are wrapped in elements with the
+// CSS classes "airport", "city", "country", "date", "time", "duration" and
+// "distance", and paragraphs are wrapped in <p> inside a <div class="itinerary">.
+type HTMLRenderer struct{}
+
+func (HTMLRenderer) Text(s string) string {
+	return html.EscapeString(s)
+}
+
+func (HTMLRenderer) AirportName(a *Airport) string {
+	return `<span class="airport" data-iata="` + html.EscapeString(a.IATA) + `" data-icao="` + html.EscapeString(a.ICAO) + `">` +
+		html.EscapeString(a.Name) + `</span>`
+}
+
+func (HTMLRenderer) City(a *Airport) string {
+	return `<span class="city">` + html.EscapeString(a.Municipality) + `</span>`
+}
+
+func (HTMLRenderer) Country(a *Airport, country string) string {
+	return `<span class="country" data-code="` + html.EscapeString(a.Country) + `">` + html.EscapeString(country) + `</span>`
+}
+
+func (HTMLRenderer) Date(t time.Time, formatted string) string {
+	return `<time class="date" datetime="` + t.Format("2006-01-02") + `">` + html.EscapeString(formatted) + `</time>`
+}
+
+func (HTMLRenderer) Time(t time.Time, formatted string) string {
+	return `<time class="time" datetime="` + t.Format(time.RFC3339) + `">` + html.EscapeString(formatted) + `</time>`
+}
+
+func (HTMLRenderer) Duration(d time.Duration, formatted string) string {
+	return `<time class="duration" datetime="` + isoDuration(d) + `">` + html.EscapeString(formatted) + `</time>`
+}
+
+func (HTMLRenderer) Distance(km float64, formatted string) string {
+	return fmt.Sprintf(`<span class="distance" data-km="%.0f">`, km) + html.EscapeString(formatted) + `</span>`
+}
+
+func (HTMLRenderer) Begin() string          { return "<div class=\"itinerary\">\n<p>" }
+func (HTMLRenderer) LineBreak() string      { return "<br>\n" }
+func (HTMLRenderer) ParagraphBreak() string { return "</p>\n<p>" }
+func (HTMLRenderer) End() string            { return "</p>\n</div>\n" }
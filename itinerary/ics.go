@@ -0,0 +1,92 @@
+package itinerary
+
+import (
+	"bufio"
+	"crypto/sha1"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// escapes of iCalendar text values
+var icsEscaper = strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\n", `\n`)
+
+// WriteICS reads the itinerary from r and writes an iCalendar with an event
+// for every line holding a time. The first time of the line starts the
+// event and the second one ends it, the first airport is its location and
+// the converted line is its summary. Times are written in UTC, so the
+// offsets of the itinerary are kept without time zone definitions.
+func (p *Prettifier) WriteICS(r io.Reader, w io.Writer) error {
+	out := bufio.NewWriter(w)
+	stamp := time.Now().UTC().Format("20060102T150405Z")
+
+	writeICSLine(out, "BEGIN:VCALENDAR")
+	writeICSLine(out, "VERSION:2.0")
+	writeICSLine(out, "PRODID:-//anyhol//itinerary//EN")
+	writeICSLine(out, "CALSCALE:GREGORIAN")
+
+	n := 0
+	err := eachLine(r, func(line string) error {
+		n++
+		segments := p.processLine(line)
+
+		var times []time.Time
+		var location *Airport
+		for _, s := range segments {
+			switch s.kind {
+			case segmentTime:
+				times = append(times, s.time)
+			case segmentAirport, segmentCity:
+				if location == nil {
+					location = s.airport
+				}
+			}
+		}
+		if len(times) == 0 { // not an event
+			return nil
+		}
+
+		summary := renderSegments(segments, PlainRenderer{})
+		writeICSLine(out, "BEGIN:VEVENT")
+		writeICSLine(out, fmt.Sprintf("UID:%x@itinerary", sha1.Sum([]byte(fmt.Sprintf("%d %s", n, line)))))
+		writeICSLine(out, "DTSTAMP:"+stamp)
+		writeICSLine(out, "DTSTART:"+icsTime(times[0]))
+		if len(times) > 1 && times[1].After(times[0]) { // an end before the start is ignored
+			writeICSLine(out, "DTEND:"+icsTime(times[1]))
+		}
+		if location != nil {
+			writeICSLine(out, "LOCATION:"+icsEscaper.Replace(location.Name))
+		}
+		writeICSLine(out, "SUMMARY:"+icsEscaper.Replace(summary))
+		writeICSLine(out, "END:VEVENT")
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	writeICSLine(out, "END:VCALENDAR")
+	return out.Flush()
+}
+
+func icsTime(t time.Time) string {
+	return t.UTC().Format("20060102T150405Z")
+}
+
+// writeICSLine writes a content line folded to 75 bytes, errors are kept by
+// bufio until Flush
+func writeICSLine(w *bufio.Writer, line string) {
+	limit := 75
+	for len(line) > limit {
+		cut := limit
+		for cut > 0 && !utf8.RuneStart(line[cut]) { // characters are never split
+			cut--
+		}
+		w.WriteString(line[:cut] + "\r\n ")
+		line = line[cut:]
+		limit = 74 // continuation lines start with a space
+	}
+	w.WriteString(line + "\r\n")
+}
@@ -0,0 +1,417 @@
+package itinerary
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"math"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// indexMagic starts every lookup index, the number is the format version
+const indexMagic = "itinerary lookup index 3\n"
+
+// A lookup index holds only what conversion needs, keyed by IATA and ICAO
+// codes, so airports are found without reading the others:
+//
+//	magic
+//	SHA-256 of the lookup CSV, 32 bytes
+//	columns the CSV was read with, a string
+//	counts of airports, IATA keys and ICAO keys, size of the string table
+//	offsets of the airports
+//	airports of the IATA keys, sorted by code
+//	airports of the ICAO keys, sorted by code
+//	string table of countries, cities and time zones
+//	airports, in the order of MapLookup.Airports:
+//	    IATA and ICAO codes and name as strings
+//	    country, municipality and time zone as uvarint offsets in the table
+//	    latitude and longitude as float64 bits
+//	CRC-32C of everything before it
+//
+// Strings are a uvarint length and the bytes, other numbers are little
+// endian uint32. Extra columns and the source file are not kept, the source
+// is the CSV the index belongs to.
+
+// IndexPath returns where the index of the lookup at path is kept.
+func IndexPath(path string) string {
+	return path + ".idx"
+}
+
+// CompileLookup reads the lookup CSV at path and writes its index next to
+// it, see IndexPath. LoadLookup uses the index while it matches the CSV.
+func CompileLookup(path string, cols Columns) (*MapLookup, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrLookupNotFound, err)
+	}
+	lookup, err := readLookup(bytes.NewReader(data), path, cols)
+	if err != nil {
+		return nil, err
+	}
+	if err := writeIndex(IndexPath(path), sha256.Sum256(data), cols, lookup); err != nil {
+		return nil, err
+	}
+	return lookup, nil
+}
+
+// loadIndexed loads the lookup from its index when the index matches the
+// CSV. A stale index is rebuilt, a missing one is not created.
+func loadIndexed(path string, data []byte, cols Columns) (*MapLookup, error) {
+	hash := sha256.Sum256(data)
+	indexPath := IndexPath(path)
+
+	if lookup, err := readIndex(indexPath, path, hash, cols); err == nil {
+		return lookup, nil
+	}
+
+	lookup, err := readLookup(bytes.NewReader(data), path, cols)
+	if err != nil {
+		return nil, err
+	}
+	if _, statErr := os.Stat(indexPath); statErr == nil { // stale
+		_ = writeIndex(indexPath, hash, cols, lookup) // an index that can't be written only makes loading slower
+	}
+	return lookup, nil
+}
+
+// errStaleIndex is returned for an index of another CSV or other columns
+var errStaleIndex = errors.New("stale lookup index")
+
+var errMalformedIndex = errors.New("malformed lookup index")
+
+// checksum table of lookup indexes
+var castagnoli = crc32.MakeTable(crc32.Castagnoli)
+
+// compiledIndex is a lookup index read into memory. Airports are decoded
+// when they are asked for and kept, so they are the same every time.
+type compiledIndex struct {
+	data    []byte
+	s       string // data as a string, strings are cut from it without copying
+	source  string // lookup CSV of the airports
+	offsets []byte // of the airports in records
+	iata    []byte // numbers of the airports of the IATA keys
+	icao    []byte // numbers of the airports of the ICAO keys
+	table   int    // start of the string table
+	records int    // start of the airports
+
+	mu       sync.Mutex
+	airports []*Airport // decoded airports by number
+}
+
+// readIndex loads the index at indexPath if it belongs to the CSV with hash
+// read with cols. Its airports get source as their source.
+func readIndex(indexPath, source string, hash [sha256.Size]byte, cols Columns) (*MapLookup, error) {
+	data, err := os.ReadFile(indexPath)
+	if err != nil {
+		return nil, err
+	}
+	if !bytes.HasPrefix(data, []byte(indexMagic)) {
+		return nil, errors.New("not a lookup index")
+	}
+	if len(data) < len(indexMagic)+4 {
+		return nil, errMalformedIndex
+	}
+	data, sum := data[:len(data)-4], binary.LittleEndian.Uint32(data[len(data)-4:])
+	if crc32.Checksum(data, castagnoli) != sum { // damaged
+		return nil, errMalformedIndex
+	}
+	d := &indexDecoder{b: data, s: string(data), pos: len(indexMagic)}
+
+	if d.pos+sha256.Size > len(d.b) || !bytes.Equal(d.b[d.pos:d.pos+sha256.Size], hash[:]) {
+		return nil, errStaleIndex
+	}
+	d.pos += sha256.Size
+	if d.string() != cols.fingerprint() {
+		return nil, errStaleIndex
+	}
+
+	n, nIATA, nICAO, tableSize := d.uint32(), d.uint32(), d.uint32(), d.uint32()
+	x := &compiledIndex{data: d.b, s: d.s, source: source}
+	x.offsets, x.iata, x.icao = d.bytes(4*uint64(n)), d.bytes(4*uint64(nIATA)), d.bytes(4*uint64(nICAO))
+	x.table = d.pos
+	x.records = x.table + tableSize
+	if d.err != nil || x.records > len(d.b) {
+		return nil, errMalformedIndex
+	}
+	if err := x.validate(); err != nil {
+		return nil, err
+	}
+	x.airports = make([]*Airport, n)
+
+	return &MapLookup{iata: make(map[string]*Airport), icao: make(map[string]*Airport), index: x}, nil
+}
+
+// validate walks every airport once, so decoding never fails later
+func (x *compiledIndex) validate() error {
+	for i := 0; i < len(x.offsets)/4; i++ {
+		d := x.decoder(i)
+		for j := 0; j < 3; j++ { // codes and name
+			d.bytes(d.uvarint())
+		}
+		for j := 0; j < 3; j++ { // entries of the table
+			if offset := d.uvarint(); offset >= uint64(x.records-x.table) {
+				return errMalformedIndex
+			}
+		}
+		if d.bytes(16); d.err != nil { // coordinates
+			return d.err
+		}
+	}
+	for _, keys := range [][]byte{x.iata, x.icao} {
+		for i := 0; i < len(keys)/4; i++ {
+			if x.number(keys, i) >= len(x.offsets)/4 {
+				return errMalformedIndex
+			}
+		}
+	}
+	return nil
+}
+
+// decode reads the airport with number i
+func (x *compiledIndex) decode(i int) (*Airport, error) {
+	d := x.decoder(i)
+	a := &Airport{Source: x.source}
+	a.IATA, a.ICAO, a.Name = d.string(), d.string(), d.string()
+	a.Country, a.Municipality, a.Timezone = x.entry(d), x.entry(d), x.entry(d)
+	a.Latitude, a.Longitude = d.float64(), d.float64()
+	return a, d.err
+}
+
+// decoder reads the airport with number i
+func (x *compiledIndex) decoder(i int) *indexDecoder {
+	d := &indexDecoder{b: x.data, s: x.s, pos: x.records + int(binary.LittleEndian.Uint32(x.offsets[4*i:]))}
+	if d.pos > len(d.b) {
+		d.pos, d.err = len(d.b), errMalformedIndex
+	}
+	return d
+}
+
+// entry reads a string of the table by its offset
+func (x *compiledIndex) entry(d *indexDecoder) string {
+	offset := d.uvarint()
+	if d.err != nil || offset >= uint64(x.records-x.table) {
+		d.err = errMalformedIndex
+		return ""
+	}
+	e := &indexDecoder{b: x.data[:x.records], s: x.s[:x.records], pos: x.table + int(offset)}
+	s := e.string()
+	d.err = e.err
+	return s
+}
+
+// airport returns the airport with number i
+func (x *compiledIndex) airport(i int) *Airport {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+	if x.airports[i] == nil {
+		x.airports[i], _ = x.decode(i) // validated when read
+	}
+	return x.airports[i]
+}
+
+// find looks code up in the IATA or ICAO keys
+func (x *compiledIndex) find(keys []byte, code string, icao bool) (*Airport, bool) {
+	key := func(k int) string {
+		d := x.decoder(x.number(keys, k))
+		if icao {
+			d.string() // IATA
+		}
+		return d.string()
+	}
+	n := len(keys) / 4
+	k := sort.Search(n, func(k int) bool { return key(k) >= code })
+	if k == n || key(k) != code {
+		return nil, false
+	}
+	return x.airport(x.number(keys, k)), true
+}
+
+// number returns the airport number of key k
+func (x *compiledIndex) number(keys []byte, k int) int {
+	return int(binary.LittleEndian.Uint32(keys[4*k:]))
+}
+
+// load moves the airports of the index into the maps of m
+func (m *MapLookup) load() {
+	x := m.index
+	if x == nil {
+		return
+	}
+	m.index = nil
+	for k := 0; k < len(x.iata)/4; k++ {
+		a := x.airport(x.number(x.iata, k))
+		m.iata[a.IATA] = a
+	}
+	for k := 0; k < len(x.icao)/4; k++ {
+		a := x.airport(x.number(x.icao, k))
+		m.icao[a.ICAO] = a
+	}
+}
+
+func writeIndex(indexPath string, hash [sha256.Size]byte, cols Columns, lookup *MapLookup) error {
+	airports := lookup.Airports()
+
+	var table, records indexEncoder
+	entries := make(map[string]uint64) // offsets in the table
+	entry := func(s string) {
+		offset, ok := entries[s]
+		if !ok {
+			offset = uint64(table.buf.Len())
+			entries[s] = offset
+			table.string(s)
+		}
+		records.uvarint(offset)
+	}
+	numbers := make(map[*Airport]uint32, len(airports))
+	var offsets indexEncoder
+	for i, a := range airports {
+		numbers[a] = uint32(i)
+		offsets.uint32(uint32(records.buf.Len()))
+		records.string(a.IATA)
+		records.string(a.ICAO)
+		records.string(a.Name)
+		entry(a.Country)
+		entry(a.Municipality)
+		entry(a.Timezone)
+		records.float64(a.Latitude)
+		records.float64(a.Longitude)
+	}
+
+	keys := func(codes map[string]*Airport) *indexEncoder {
+		sorted := make([]string, 0, len(codes))
+		for code := range codes {
+			sorted = append(sorted, code)
+		}
+		sort.Strings(sorted)
+		var e indexEncoder
+		for _, code := range sorted {
+			e.uint32(numbers[codes[code]])
+		}
+		return &e
+	}
+	lookup.load()
+	iata, icao := keys(lookup.iata), keys(lookup.icao)
+
+	var e indexEncoder
+	e.buf.WriteString(indexMagic)
+	e.buf.Write(hash[:])
+	e.string(cols.fingerprint())
+	e.uint32(uint32(len(airports)))
+	e.uint32(uint32(len(lookup.iata)))
+	e.uint32(uint32(len(lookup.icao)))
+	e.uint32(uint32(table.buf.Len()))
+
+	return WriteFileAtomic(indexPath, func(w io.Writer) error {
+		sum := crc32.New(castagnoli)
+		for _, part := range []*indexEncoder{&e, &offsets, iata, icao, &table, &records} {
+			sum.Write(part.buf.Bytes())
+			if _, err := w.Write(part.buf.Bytes()); err != nil {
+				return err
+			}
+		}
+		_, err := w.Write(binary.LittleEndian.AppendUint32(nil, sum.Sum32()))
+		return err
+	})
+}
+
+// indexEncoder writes the values of a lookup index
+type indexEncoder struct {
+	buf     bytes.Buffer
+	scratch [binary.MaxVarintLen64]byte
+}
+
+func (e *indexEncoder) uvarint(n uint64) {
+	e.buf.Write(e.scratch[:binary.PutUvarint(e.scratch[:], n)])
+}
+
+func (e *indexEncoder) uint32(n uint32) {
+	e.buf.Write(binary.LittleEndian.AppendUint32(e.scratch[:0], n))
+}
+
+func (e *indexEncoder) string(s string) {
+	e.uvarint(uint64(len(s)))
+	e.buf.WriteString(s)
+}
+
+func (e *indexEncoder) float64(f float64) {
+	e.buf.Write(binary.LittleEndian.AppendUint64(e.scratch[:0], math.Float64bits(f)))
+}
+
+// indexDecoder reads the values of a lookup index. After the first error
+// it only returns zero values, so err is checked once at the end.
+type indexDecoder struct {
+	b   []byte
+	s   string // b as a string
+	pos int
+	err error
+}
+
+func (d *indexDecoder) uvarint() uint64 {
+	if d.err != nil {
+		return 0
+	}
+	n, size := binary.Uvarint(d.b[d.pos:])
+	if size <= 0 {
+		d.err = errMalformedIndex
+		return 0
+	}
+	d.pos += size
+	return n
+}
+
+func (d *indexDecoder) uint32() int {
+	b := d.bytes(4)
+	if b == nil {
+		return 0
+	}
+	return int(binary.LittleEndian.Uint32(b))
+}
+
+// bytes returns the next n bytes, n is checked before it is converted so
+// a damaged length never wraps around
+func (d *indexDecoder) bytes(n uint64) []byte {
+	if d.err != nil || n > uint64(len(d.b)-d.pos) {
+		d.err = errMalformedIndex
+		return nil
+	}
+	b := d.b[d.pos : d.pos+int(n)]
+	d.pos += int(n)
+	return b
+}
+
+func (d *indexDecoder) string() string {
+	n := d.uvarint()
+	if d.err != nil || n > uint64(len(d.b)-d.pos) {
+		d.err = errMalformedIndex
+		return ""
+	}
+	s := d.s[d.pos : d.pos+int(n)]
+	d.pos += int(n)
+	return s
+}
+
+func (d *indexDecoder) float64() float64 {
+	b := d.bytes(8)
+	if b == nil {
+		return 0
+	}
+	return math.Float64frombits(binary.LittleEndian.Uint64(b))
+}
+
+// fingerprint describes the mapping, indexes are only used with the mapping
+// they were compiled with
+func (c Columns) fingerprint() string {
+	fields := make([]string, 0, len(c))
+	for field, names := range c {
+		fields = append(fields, field+"="+strings.Join(names, "|"))
+	}
+	sort.Strings(fields)
+	return strings.Join(fields, ";")
+}
@@ -0,0 +1,127 @@
+// Package itinerary converts airport codes, dates and times found in
+// itinerary text into a human readable form.
+package itinerary
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"strings"
+)
+
+// Options changes how a Prettifier converts its input.
+type Options struct {
+	Renderer Renderer // how converted text looks, plain text when nil
+	Locale   *Locale  // how dates and times are written, English when nil
+	Extended bool     // converts the tokens of extended mode too, e.g. L24(...)
+
+	// DayOffsets marks arrival times on a later date than their departure,
+	// the time before them on the line, with "+1", "+2" and so on.
+	DayOffsets bool
+
+	CountryCodes bool         // countries are written as ISO 3166 codes instead of names
+	Unit         DistanceUnit // unit of distances, kilometers when empty
+}
+
+// Prettifier converts itinerary text using an airport lookup.
+type Prettifier struct {
+	lookup Lookup
+	opts   Options
+}
+
+// Target is an output together with the renderer used for it.
+type Target struct {
+	W        io.Writer
+	Renderer Renderer
+}
+
+// New returns a Prettifier that resolves airport codes with lookup.
+func New(lookup Lookup, opts Options) *Prettifier {
+	if opts.Renderer == nil {
+		opts.Renderer = PlainRenderer{}
+	}
+	if opts.Locale == nil {
+		opts.Locale = &English
+	}
+	if !opts.Unit.Valid() {
+		opts.Unit = Kilometers
+	}
+	return &Prettifier{lookup: lookup, opts: opts}
+}
+
+// Line converts the codes, dates and times of a single line.
+func (p *Prettifier) Line(line string) string {
+	return renderSegments(p.processLine(line), p.opts.Renderer)
+}
+
+// Convert reads the itinerary from r and writes the converted text to w.
+// The text is converted and written line by line, so memory use does not
+// grow with the input. Lines may be of any length, but each line is held
+// whole while it is converted, as tokens, space trimming and day offsets
+// span it: memory grows with the longest line.
+func (p *Prettifier) Convert(r io.Reader, w io.Writer) error {
+	return p.ConvertTo(r, Target{W: w, Renderer: p.opts.Renderer})
+}
+
+// ConvertTo works like Convert, but converts every line once and writes it
+// to all targets, each rendered by its own renderer.
+func (p *Prettifier) ConvertTo(r io.Reader, targets ...Target) error {
+	outs := make([]*lineWriter, len(targets))
+	for i, target := range targets {
+		outs[i] = newLineWriter(target.W, target.Renderer)
+	}
+
+	err := eachLine(r, func(line string) error {
+		segments := p.processLine(line) // converting line by line
+		for _, out := range outs {
+			if err := out.WriteLine(segments); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, out := range outs {
+		if err := out.Close(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// eachLine reads r line by line and calls f with every line broken on
+// \v \f \r and with excessive space trimmed. A line is read whole before f
+// is called, however long it is.
+func eachLine(r io.Reader, f func(line string) error) error {
+	reader := bufio.NewReader(r)
+	for {
+		line, err := reader.ReadString('\n') // reading input
+		if err != nil && !errors.Is(err, io.EOF) {
+			return err
+		}
+		if line == "" && err != nil { // input is over
+			return nil
+		}
+
+		line = strings.TrimSuffix(line, "\n")
+		line = strings.TrimSuffix(line, "\r") // \r\n line endings
+		for _, part := range splitLineBreaks(line) {
+			if ferr := f(trimSpaces(part)); ferr != nil {
+				return ferr
+			}
+		}
+		if err != nil {
+			return nil
+		}
+	}
+}
+
+// Document converts a whole itinerary held in memory.
+func (p *Prettifier) Document(text string) string {
+	var result strings.Builder
+	_ = p.Convert(strings.NewReader(text), &result) // strings never fail
+	return result.String()
+}
@@ -0,0 +1,107 @@
+package itinerary
+
+import (
+	"encoding/json"
+	"io"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// Token describes a token of the input and what it was converted into.
+type Token struct {
+	Token    string     `json:"token"`  // e.g. "##EDDW" or "T12(2069-04-24T19:18-02:00)"
+	Kind     string     `json:"kind"`   // airport, city, date, time12, time24 or a kind of extended mode
+	Offset   int        `json:"offset"` // byte offset in the input
+	Line     int        `json:"line"`   // line number starting from 1
+	Column   int        `json:"column"` // column in characters starting from 1
+	Resolved bool       `json:"resolved"`
+	Value    string     `json:"value,omitempty"`       // converted text
+	Time     *time.Time `json:"time,omitempty"`        // parsed timestamp of dates and times
+	Duration string     `json:"duration,omitempty"`    // ISO 8601 duration, e.g. "PT14H41M"
+	Distance float64    `json:"distance_km,omitempty"` // great-circle distance in kilometers
+	Source   string     `json:"source,omitempty"`      // lookup file the value comes from
+	Error    string     `json:"error,omitempty"`       // why a token is flagged
+	Airport  *Airport   `json:"airport,omitempty"`     // resolved airport of airports, cities and countries
+}
+
+// Description is the converted text of an itinerary with its tokens.
+type Description struct {
+	Text   string  `json:"text"`
+	Tokens []Token `json:"tokens"`
+}
+
+// Describe reads the itinerary from r and returns the plain converted text
+// together with every token found in it. Unlike Convert, it holds the whole
+// itinerary in memory.
+func (p *Prettifier) Describe(r io.Reader) (*Description, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var text strings.Builder
+	if err := p.ConvertTo(strings.NewReader(string(data)), Target{W: &text, Renderer: PlainRenderer{}}); err != nil {
+		return nil, err
+	}
+	d := &Description{Text: text.String(), Tokens: []Token{}}
+
+	offset := 0
+	for n, line := range strings.SplitAfter(string(data), "\n") {
+		start := 0 // of the part in the line
+		for _, part := range splitLineBreaks(line) {
+			for _, s := range p.processLine(part) { // the segments of the text, day offsets included
+				if s.token == nil {
+					continue
+				}
+				at := start + s.token.start
+				d.Tokens = append(d.Tokens, describeToken(s, offset+at, n+1, utf8.RuneCountInString(line[:at])+1))
+			}
+			start += len(part) + 1 // and the line break
+		}
+		offset += len(line)
+	}
+
+	return d, nil
+}
+
+// describeToken builds the record of the token a segment was converted from
+func describeToken(s segment, offset, line, column int) Token {
+	tok := s.token
+	t := Token{
+		Token:    tok.text,
+		Kind:     tok.kind.String(),
+		Offset:   offset,
+		Line:     line,
+		Column:   column,
+		Resolved: s.kind != segmentText, // left as it is
+	}
+	switch s.kind {
+	case segmentAirport:
+		t.Value, t.Airport, t.Source = s.airport.Name, s.airport, s.airport.SourceOf(FieldName)
+	case segmentCity:
+		t.Value, t.Airport, t.Source = s.airport.Municipality, s.airport, s.airport.SourceOf(FieldMunicipality)
+	case segmentDate, segmentTime:
+		t.Value, t.Time = s.text, &s.time
+	case segmentCountry:
+		t.Value, t.Airport, t.Source = s.text, s.airport, s.airport.SourceOf(FieldCountry)
+	case segmentDistance:
+		t.Value, t.Distance = s.text, s.distance
+	case segmentDuration:
+		t.Value, t.Duration = s.text, isoDuration(s.duration)
+	}
+	t.Error = s.problem
+	return t
+}
+
+// WriteJSON reads the itinerary from r and writes its Description to w as
+// indented JSON.
+func (p *Prettifier) WriteJSON(r io.Reader, w io.Writer) error {
+	d, err := p.Describe(r)
+	if err != nil {
+		return err
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(d)
+}
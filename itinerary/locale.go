@@ -0,0 +1,100 @@
+package itinerary
+
+import (
+	"strings"
+	"time"
+)
+
+// Locale holds the names and conventions used to write dates and times.
+type Locale struct {
+	Months   [12]string // month names written for "Jan" in Date
+	Weekdays [7]string  // weekday names from Sunday written for "Mon" in Date
+	Date     string     // layout of dates like in time.Format, e.g. "02 Jan 2006"
+	AM, PM   string     // written after 12 hour times
+	Hour24   bool       // T12(...) is written with 24 hours too
+}
+
+// English is the default locale, it gives exactly the output of the task.
+var English = Locale{
+	Months:   [12]string{"Jan", "Feb", "Mar", "Apr", "May", "Jun", "Jul", "Aug", "Sep", "Oct", "Nov", "Dec"},
+	Weekdays: [7]string{"Sun", "Mon", "Tue", "Wed", "Thu", "Fri", "Sat"},
+	Date:     "02 Jan 2006",
+	AM:       "AM",
+	PM:       "PM",
+}
+
+// locales by lower case name
+var locales = map[string]*Locale{
+	"en": &English,
+	"en-us": {
+		Months:   English.Months,
+		Weekdays: English.Weekdays,
+		Date:     "Jan 02, 2006",
+		AM:       "AM",
+		PM:       "PM",
+	},
+	"de": {
+		Months:   [12]string{"Jan.", "Feb.", "März", "Apr.", "Mai", "Juni", "Juli", "Aug.", "Sept.", "Okt.", "Nov.", "Dez."},
+		Weekdays: [7]string{"So.", "Mo.", "Di.", "Mi.", "Do.", "Fr.", "Sa."},
+		Date:     "2. Jan 2006",
+		Hour24:   true,
+	},
+	"fr": {
+		Months:   [12]string{"janv.", "févr.", "mars", "avr.", "mai", "juin", "juil.", "août", "sept.", "oct.", "nov.", "déc."},
+		Weekdays: [7]string{"dim.", "lun.", "mar.", "mer.", "jeu.", "ven.", "sam."},
+		Date:     "2 Jan 2006",
+		Hour24:   true,
+	},
+	"es": {
+		Months:   [12]string{"ene", "feb", "mar", "abr", "may", "jun", "jul", "ago", "sept", "oct", "nov", "dic"},
+		Weekdays: [7]string{"dom", "lun", "mar", "mié", "jue", "vie", "sáb"},
+		Date:     "2 Jan 2006",
+		AM:       " a. m.",
+		PM:       " p. m.",
+	},
+	"it": {
+		Months:   [12]string{"gen", "feb", "mar", "apr", "mag", "giu", "lug", "ago", "set", "ott", "nov", "dic"},
+		Weekdays: [7]string{"dom", "lun", "mar", "mer", "gio", "ven", "sab"},
+		Date:     "2 Jan 2006",
+		Hour24:   true,
+	},
+	"ru": {
+		Months:   [12]string{"янв.", "февр.", "мар.", "апр.", "мая", "июн.", "июл.", "авг.", "сент.", "окт.", "нояб.", "дек."},
+		Weekdays: [7]string{"вс", "пн", "вт", "ср", "чт", "пт", "сб"},
+		Date:     "2 Jan 2006 г.",
+		Hour24:   true,
+	},
+	"ja": {
+		Months:   [12]string{"1月", "2月", "3月", "4月", "5月", "6月", "7月", "8月", "9月", "10月", "11月", "12月"},
+		Weekdays: [7]string{"日", "月", "火", "水", "木", "金", "土"},
+		Date:     "2006年Jan2日",
+		Hour24:   true,
+	},
+}
+
+// RegisterLocale adds a locale or replaces a built in one. It is meant to
+// be called before converting, e.g. from init.
+func RegisterLocale(name string, l Locale) {
+	locales[strings.ToLower(name)] = &l
+}
+
+// LocaleByName finds a locale like "de" or "en-US". When there is no
+// locale of the region, the locale of the language is used.
+func LocaleByName(name string) (*Locale, bool) {
+	name = strings.ToLower(strings.ReplaceAll(name, "_", "-"))
+	if l, ok := locales[name]; ok {
+		return l, true
+	}
+	language, _, _ := strings.Cut(name, "-")
+	l, ok := locales[language]
+	return l, ok
+}
+
+// names in date layouts become placeholders, time.Format keeps them as they are
+var dateNames = strings.NewReplacer("Jan", "\x00", "Mon", "\x01")
+
+// formatDate writes a date with the names of the locale
+func (l *Locale) formatDate(t time.Time) string {
+	formatted := t.Format(dateNames.Replace(l.Date))
+	return strings.NewReplacer("\x00", l.Months[t.Month()-1], "\x01", l.Weekdays[t.Weekday()]).Replace(formatted)
+}
@@ -0,0 +1,37 @@
+package itinerary
+
+import (
+	"fmt"
+	"strings"
+	"time"
+	_ "time/tzdata" // time zones work without the zone database of the system
+)
+
+// localTime converts the instant of an L12 or L24 token into the time zone
+// of its airport, e.g. L24(2024-07-23T15:29Z, #HEL)
+func (p *Prettifier) localTime(tok token) (time.Time, error) {
+	iso, code, found := strings.Cut(tok.arg, ",")
+	if !found {
+		return time.Time{}, fmt.Errorf("want %s(time, #IATA)", tok.text[:3])
+	}
+
+	t, err := parseISOTime(strings.TrimSpace(iso))
+	if err != nil {
+		return time.Time{}, fmt.Errorf("malformed time %s", strings.TrimSpace(iso))
+	}
+
+	code = strings.TrimSpace(code)
+	airport, err := p.airportRef(code)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if airport.Timezone == "" {
+		return time.Time{}, fmt.Errorf("no time zone of %s in the lookup", code)
+	}
+	zone, err := time.LoadLocation(airport.Timezone)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("unknown time zone %q of %s", airport.Timezone, code)
+	}
+
+	return t.In(zone), nil
+}
@@ -0,0 +1,253 @@
+package itinerary
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// LoadLookup reads the airport lookup CSV at path. Columns are found by
+// their header names, so they may come in any order. When the lookup was
+// compiled with CompileLookup, its index is used instead while it matches
+// the CSV, and rebuilt when the CSV has changed.
+func LoadLookup(path string, cols Columns) (*MapLookup, error) {
+	data, err := os.ReadFile(path) // open lookup
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrLookupNotFound, err)
+	}
+
+	return loadIndexed(path, data, cols)
+}
+
+// LoadLookups reads the lookup at path and applies the overlays on top of it
+// in order, so later files win. See ApplyOverlay for the overlay format.
+func LoadLookups(cols Columns, path string, overlays ...string) (*MapLookup, error) {
+	lookup, err := LoadLookup(path, cols)
+	if err != nil {
+		return nil, err
+	}
+	for _, overlay := range overlays {
+		o, err := os.Open(overlay)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrLookupNotFound, err)
+		}
+		err = lookup.ApplyOverlay(o, overlay, cols)
+		o.Close()
+		if err != nil {
+			return nil, err
+		}
+	}
+	return lookup, nil
+}
+
+// readLookup parses the lookup CSV record by record. Quoted fields may hold
+// commas, escaped quotes and newlines.
+func readLookup(r io.Reader, name string, cols Columns) (*MapLookup, error) {
+	lookup := NewMapLookup()
+	err := readRecords(r, name, cols, allFields(), Fields, func(header, parts []string, positions map[string]int) *MalformedLookupError {
+		for _, field := range Fields {
+			if strings.TrimSpace(parts[positions[field]]) == "" {
+				return &MalformedLookupError{Column: positions[field] + 1, Field: field, Reason: "blank field"}
+			}
+		}
+		airport := &Airport{Source: name}
+		if e := airport.setFields(header, parts, positions); e != nil {
+			return e
+		}
+		lookup.Add(airport)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return lookup, nil
+}
+
+// ApplyOverlay reads an overlay lookup CSV and applies it field by field.
+// An overlay needs an iata_code or icao_code column to find its airports
+// and may leave out any other column. Blank fields keep the value below,
+// and a delete column holding "true", "yes" or "1" removes the airport.
+// Airports that are not in the lookup yet are added and need every field.
+// Values keep the name of the overlay they come from, see Airport.SourceOf.
+func (m *MapLookup) ApplyOverlay(r io.Reader, name string, cols Columns) error {
+	return readRecords(r, name, cols, overlayFields(), nil, func(header, parts []string, positions map[string]int) *MalformedLookupError {
+		value := func(field string) string {
+			if i, found := positions[field]; found {
+				return strings.TrimSpace(parts[i])
+			}
+			return ""
+		}
+
+		iata, icao := value(FieldIATA), value(FieldICAO)
+		if iata == "" && icao == "" {
+			return &MalformedLookupError{Reason: "no iata_code or icao_code"}
+		}
+		existing, found := m.ByIATA(iata)
+		if !found {
+			existing, found = m.ByICAO(icao)
+		}
+
+		if isTrue(value(FieldDelete)) {
+			if found {
+				m.Remove(existing)
+			}
+			return nil
+		}
+
+		airport := &Airport{Source: name}
+		if found {
+			copied := *existing
+			airport = &copied
+			m.Remove(existing)
+		} else {
+			for _, field := range Fields {
+				if value(field) == "" {
+					return &MalformedLookupError{Field: field, Reason: "new airport without the field"}
+				}
+			}
+		}
+		before := *airport
+		if e := airport.setFields(header, parts, positions); e != nil {
+			return e
+		}
+		if found {
+			airport.overridden(&before, name)
+		}
+		m.Add(airport)
+		return nil
+	})
+}
+
+// readRecords reads the header and calls record for every other row
+func readRecords(r io.Reader, name string, cols Columns, fields, required []string, record func(header, parts []string, positions map[string]int) *MalformedLookupError) error {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1 // rows are checked below
+	reader.ReuseRecord = true
+
+	var header []string
+	var positions map[string]int // field positions found in the header
+	for row := 1; ; row++ {
+		parts, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil { // broken quoting
+			e := &MalformedLookupError{File: name, Row: row, Err: err}
+			var parseErr *csv.ParseError
+			if errors.As(err, &parseErr) {
+				e.Line, e.Err = parseErr.StartLine, parseErr.Err
+			}
+			return e
+		}
+		line, _ := reader.FieldPos(0)
+
+		if positions == nil { // header row
+			if len(parts) > 0 {
+				parts[0] = strings.TrimPrefix(parts[0], "\uFEFF") // byte order mark
+			}
+			if positions, err = cols.resolve(parts, fields, required); err != nil {
+				var e *MalformedLookupError
+				if errors.As(err, &e) {
+					e.File, e.Line = name, line
+				}
+				return err
+			}
+			header = append([]string(nil), parts...) // records are reused
+			continue
+		}
+
+		if len(parts) != len(header) {
+			return &MalformedLookupError{File: name, Row: row, Line: line, Reason: fmt.Sprintf("%d columns, header has %d", len(parts), len(header))}
+		}
+		if e := record(header, parts, positions); e != nil {
+			e.File, e.Row, e.Line = name, row, line
+			return e
+		}
+	}
+	if positions == nil {
+		return &MalformedLookupError{File: name, Reason: "no header"}
+	}
+
+	return nil
+}
+
+// setFields sets the fields of a lookup record, blank fields are skipped
+func (a *Airport) setFields(header, parts []string, positions map[string]int) *MalformedLookupError {
+	for _, field := range allFields() {
+		i, found := positions[field]
+		if !found || strings.TrimSpace(parts[i]) == "" {
+			continue
+		}
+		if err := a.set(field, parts[i]); err != nil {
+			return &MalformedLookupError{Column: i + 1, Field: field, Reason: err.Error()}
+		}
+	}
+
+	extra := make(map[string]string, len(a.Extra)) // airports of overlays share nothing
+	for column, value := range a.Extra {
+		extra[column] = value
+	}
+	for i, column := range header {
+		if isPosition(positions, i) || (extra[column] != "" && strings.TrimSpace(parts[i]) == "") {
+			continue
+		}
+		extra[column] = parts[i] // columns we don't use
+	}
+	if len(extra) > 0 {
+		a.Extra = extra
+	}
+	return nil
+}
+
+// set sets a single field from its lookup value
+func (a *Airport) set(field, value string) error {
+	switch field {
+	case FieldName:
+		a.Name = value
+	case FieldCountry:
+		a.Country = value
+	case FieldMunicipality:
+		a.Municipality = value
+	case FieldICAO:
+		a.ICAO = value
+	case FieldIATA:
+		a.IATA = value
+	case FieldTimezone:
+		a.Timezone = strings.TrimSpace(value)
+	case FieldCoordinates: // coordinates are "longitude, latitude"
+		malformed := errors.New(`want "longitude, latitude"`)
+		lon, lat, found := strings.Cut(value, ",")
+		if !found {
+			return malformed
+		}
+		longitude, err := strconv.ParseFloat(strings.TrimSpace(lon), 64)
+		if err != nil {
+			return malformed
+		}
+		latitude, err := strconv.ParseFloat(strings.TrimSpace(lat), 64)
+		if err != nil {
+			return malformed
+		}
+		a.Longitude, a.Latitude = longitude, latitude
+	}
+	return nil
+}
+
+func isPosition(positions map[string]int, i int) bool {
+	for _, p := range positions {
+		if p == i {
+			return true
+		}
+	}
+	return false
+}
+
+// isTrue reports whether a delete column asks for deletion
+func isTrue(value string) bool {
+	b, err := strconv.ParseBool(value)
+	return (err == nil && b) || strings.EqualFold(value, "yes")
+}
@@ -0,0 +1,77 @@
+package itinerary
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// characters that are Markdown anywhere in a line
+var markdownEscaper = strings.NewReplacer(
+	`\`, `\\`, "`", "\\`", `*`, `\*`, `_`, `\_`, `[`, `\[`, `]`, `\]`,
+	`<`, `\<`, `>`, `\>`, `#`, `\#`, `|`, `\|`, `~`, `\~`, `!`, `\!`,
+	`&`, `\&`,
+)
+
+// lists and setext headings at the start of a line: "- ", "+ ", "1. ", "2) ", "==="
+var markdownLineStart = regexp.MustCompile(`^(\d+[.)]|[-+=])`)
+
+// indentation at the start of a line that makes a code block: a tab or 4 spaces
+var markdownIndent = regexp.MustCompile(`^( {0,3}\t| {4})`)
+
+// MarkdownRenderer writes converted text as Markdown. Markdown characters of
+// the text are escaped, so codes left as they are never become headings.
+// Airports are bold names, or "name (IATA)" with Codes, "name (ICAO)" when
+// there is no IATA code. Lines of a paragraph are joined with hard line
+// breaks.
+type MarkdownRenderer struct {
+	Codes bool // airports as "name (IATA)" instead of bold names
+}
+
+func (MarkdownRenderer) Text(s string) string {
+	return escapeMarkdown(s)
+}
+
+func (m MarkdownRenderer) AirportName(a *Airport) string {
+	if m.Codes {
+		code := a.IATA
+		if code == "" {
+			code = a.ICAO
+		}
+		if code == "" {
+			return escapeMarkdown(a.Name)
+		}
+		return escapeMarkdown(a.Name) + " (" + escapeMarkdown(code) + ")"
+	}
+	return "**" + escapeMarkdown(a.Name) + "**"
+}
+
+func (MarkdownRenderer) City(a *Airport) string {
+	return escapeMarkdown(a.Municipality)
+}
+
+func (MarkdownRenderer) Date(t time.Time, formatted string) string {
+	return escapeMarkdown(formatted)
+}
+
+func (MarkdownRenderer) Time(t time.Time, formatted string) string {
+	return escapeMarkdown(formatted)
+}
+
+func (MarkdownRenderer) Begin() string          { return "" }
+func (MarkdownRenderer) LineBreak() string      { return "\\\n" }
+func (MarkdownRenderer) ParagraphBreak() string { return "\n\n" }
+func (MarkdownRenderer) End() string            { return "\n" }
+
+// escapeMarkdown escapes s so it is shown as it is. The start of s is
+// escaped like the start of a line, as the renderer can't tell where it is.
+// Indentation that would make a code block starts with an entity instead.
+func escapeMarkdown(s string) string {
+	s = markdownLineStart.ReplaceAllStringFunc(markdownEscaper.Replace(s), func(m string) string {
+		return m[:len(m)-1] + `\` + m[len(m)-1:]
+	})
+	return markdownIndent.ReplaceAllStringFunc(s, func(m string) string {
+		return fmt.Sprintf("&#%d;", m[0]) + m[1:]
+	})
+}
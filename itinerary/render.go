@@ -0,0 +1,92 @@
+package itinerary
+
+import "time"
+
+// Renderer decides how converted text looks in the output. The conversion
+// calls it for every piece of a line, so the same conversion can be
+// written as plain text, coloured text or any other format.
+type Renderer interface {
+	Text(s string) string                      // text around tokens and tokens left as they are
+	AirportName(a *Airport) string             // #IATA and ##ICAO
+	City(a *Airport) string                    // *#IATA and *##ICAO
+	Date(t time.Time, formatted string) string // D(...)
+	Time(t time.Time, formatted string) string // T12(...) and T24(...)
+}
+
+// BlockRenderer is a Renderer that also marks up the structure of the
+// document. Lines separated by blank lines are paragraphs, and the blank
+// lines are collapsed the same way as in plain text.
+type BlockRenderer interface {
+	Renderer
+	Begin() string          // before the first line
+	LineBreak() string      // between lines of a paragraph
+	ParagraphBreak() string // between paragraphs
+	End() string            // after the last line
+}
+
+// DurationRenderer is a Renderer that also decides how durations look.
+// Durations of other renderers are written as text.
+type DurationRenderer interface {
+	Renderer
+	Duration(d time.Duration, formatted string) string // DUR(...)
+}
+
+// CountryRenderer is a Renderer that also decides how countries look.
+// Countries of other renderers are written as text.
+type CountryRenderer interface {
+	Renderer
+	Country(a *Airport, country string) string // ^#IATA and ^##ICAO
+}
+
+// DistanceRenderer is a Renderer that also decides how distances look.
+// Distances of other renderers are written as text.
+type DistanceRenderer interface {
+	Renderer
+	Distance(km float64, formatted string) string // DIST(...)
+}
+
+// PlainRenderer writes converted text as it is.
+type PlainRenderer struct{}
+
+func (PlainRenderer) Text(s string) string                      { return s }
+func (PlainRenderer) AirportName(a *Airport) string             { return a.Name }
+func (PlainRenderer) City(a *Airport) string                    { return a.Municipality }
+func (PlainRenderer) Date(t time.Time, formatted string) string { return formatted }
+func (PlainRenderer) Time(t time.Time, formatted string) string { return formatted }
+
+// ANSIRenderer colours converted values with ANSI escape codes for terminals.
+type ANSIRenderer struct{}
+
+const ansiReset = "\033[0m"
+
+func (ANSIRenderer) Text(s string) string {
+	return s
+}
+
+func (ANSIRenderer) AirportName(a *Airport) string {
+	return "\033[36m" + a.Name + ansiReset // cyan
+}
+
+func (ANSIRenderer) City(a *Airport) string {
+	return "\033[34m" + a.Municipality + ansiReset // blue
+}
+
+func (ANSIRenderer) Date(t time.Time, formatted string) string {
+	return "\033[42m\033[1m\033[37m" + formatted + ansiReset // bold white on green
+}
+
+func (ANSIRenderer) Time(t time.Time, formatted string) string {
+	return "\033[40m\033[32m" + formatted + ansiReset // green on black
+}
+
+func (ANSIRenderer) Country(a *Airport, country string) string {
+	return "\033[35m" + country + ansiReset // magenta
+}
+
+func (ANSIRenderer) Distance(km float64, formatted string) string {
+	return "\033[33m" + formatted + ansiReset // yellow
+}
+
+func (ANSIRenderer) Duration(d time.Duration, formatted string) string {
+	return "\033[33m" + formatted + ansiReset // yellow
+}
@@ -0,0 +1,145 @@
+package itinerary
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// AirportLister is a Lookup that can list its airports, like MapLookup.
+type AirportLister interface {
+	Lookup
+	Airports() []*Airport
+}
+
+// ranks of a search match, lower is better
+const (
+	rankCode     = iota // a code is the query, e.g. "hel"
+	rankExact           // a name, city or country is the query
+	rankPrefix          // a word starts with the query, e.g. "vant"
+	rankContains        // the query is somewhere inside
+	rankNone
+)
+
+// Match is an airport found by Search.
+type Match struct {
+	Airport *Airport `json:"airport"`
+	Tokens  []string `json:"tokens"` // tokens of the airport, e.g. "#HEL" and "##EFHK"
+	Field   string   `json:"field"`  // field that matched best, e.g. "municipality"
+	Rank    int      `json:"rank"`   // 0 for a code, then exact, prefix and partial matches
+}
+
+// Search finds the airports of the lister whose name, municipality, country
+// or codes match every word of the query, ignoring case. Countries match by
+// code and by name. Matches are ranked, whole codes first, then exact, prefix
+// and partial matches, and at most limit are returned (all when limit is 0).
+func Search(lister AirportLister, query string, limit int) []Match {
+	terms := strings.Fields(strings.ToLower(query))
+	if len(terms) == 0 {
+		return nil
+	}
+
+	var matches []Match
+	for _, a := range lister.Airports() {
+		m, ok := match(a, terms)
+		if ok {
+			matches = append(matches, m)
+		}
+	}
+
+	sort.SliceStable(matches, func(i, j int) bool { // Airports is sorted by code already
+		if matches[i].Rank != matches[j].Rank {
+			return matches[i].Rank < matches[j].Rank
+		}
+		return fieldOrder(matches[i].Field) < fieldOrder(matches[j].Field)
+	})
+	if limit > 0 && len(matches) > limit {
+		matches = matches[:limit]
+	}
+	return matches
+}
+
+// searchFields are the fields searched, in the order they win ties. Codes
+// are last, so a code that only starts with the query, like HELX for "hel",
+// never comes before a name that does. Whole codes rank first anyway.
+var searchFields = []string{FieldName, FieldMunicipality, FieldCountry, FieldIATA, FieldICAO}
+
+func fieldOrder(field string) int {
+	for i, f := range searchFields {
+		if f == field {
+			return i
+		}
+	}
+	return len(searchFields)
+}
+
+// match ranks an airport against the lower case words of a query. The rank
+// is the sum of the best rank of every word.
+func match(a *Airport, terms []string) (Match, bool) {
+	values := map[string][]string{
+		FieldIATA:         {a.IATA},
+		FieldICAO:         {a.ICAO},
+		FieldName:         {a.Name},
+		FieldMunicipality: {a.Municipality},
+		FieldCountry:      {a.Country, countries[strings.ToUpper(a.Country)]},
+	}
+
+	m := Match{Airport: a, Tokens: airportTokens(a)}
+	best := rankNone
+	for _, term := range terms {
+		termBest := rankNone
+		for _, field := range searchFields {
+			for _, value := range values[field] {
+				r := rankValue(strings.ToLower(value), term, field == FieldIATA || field == FieldICAO)
+				if r < termBest {
+					termBest = r
+				}
+				if r < best {
+					best, m.Field = r, field
+				}
+			}
+		}
+		if termBest == rankNone { // every word has to match
+			return Match{}, false
+		}
+		m.Rank += termBest
+	}
+	return m, true
+}
+
+// rankValue ranks a single lower case value against a word of the query
+func rankValue(value, term string, code bool) int {
+	switch {
+	case value == "":
+		return rankNone
+	case value == term && code:
+		return rankCode
+	case value == term:
+		return rankExact
+	case strings.HasPrefix(value, term):
+		return rankPrefix
+	}
+	for _, word := range strings.FieldsFunc(value, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}) {
+		if strings.HasPrefix(word, term) {
+			return rankPrefix
+		}
+	}
+	if strings.Contains(value, term) {
+		return rankContains
+	}
+	return rankNone
+}
+
+// airportTokens returns the airport tokens that resolve to a
+func airportTokens(a *Airport) []string {
+	tokens := []string{}
+	if a.IATA != "" {
+		tokens = append(tokens, "#"+a.IATA)
+	}
+	if a.ICAO != "" {
+		tokens = append(tokens, "##"+a.ICAO)
+	}
+	return tokens
+}
@@ -0,0 +1,154 @@
+package itinerary
+
+import (
+	"math"
+	"sort"
+	"strings"
+)
+
+// maxSuggestions is how many codes are suggested for an unresolved code
+const maxSuggestions = 3
+
+// keyboard rows of QWERTY, each shifted to the right of the one above
+var keyboardRows = [...]struct {
+	keys  string
+	shift float64
+}{
+	{"QWERTYUIOP", 0},
+	{"ASDFGHJKL", 0.25},
+	{"ZXCVBNM", 0.75},
+}
+
+// keyPositions holds the row and the horizontal position of the keys A to Z
+var keyPositions = func() (positions [26][2]float64) {
+	for row, r := range keyboardRows {
+		for i := 0; i < len(r.keys); i++ {
+			positions[r.keys[i]-'A'] = [2]float64{float64(row), float64(i) + r.shift}
+		}
+	}
+	return positions
+}()
+
+// adjacentKeys reports whether the letters a and b are next to each other
+// on a keyboard
+func adjacentKeys(a, b byte) bool {
+	if a == b || a < 'A' || a > 'Z' || b < 'A' || b > 'Z' {
+		return false
+	}
+	pa, pb := keyPositions[a-'A'], keyPositions[b-'A']
+	return math.Abs(pa[0]-pb[0]) <= 1 && math.Abs(pa[1]-pb[1]) <= 1
+}
+
+// what typos cost. A swap of two letters is the most likely typo, then a
+// slip onto a neighbouring key. Two slips cost as much as a wrong key.
+const (
+	costSwap     = 0.4
+	costSlip     = 0.5
+	costWrongKey = 1.0
+)
+
+// typos returns the codes one typo away from code with what they cost, see
+// costSwap. Only letters are put in, other codes are never tokens. Codes are
+// never longer or shorter, those are left out as costing more.
+func typos(code string) map[string]float64 {
+	costs := make(map[string]float64)
+	add := func(b []byte, cost float64) {
+		if c, ok := costs[string(b)]; !ok || cost < c {
+			costs[string(b)] = cost
+		}
+	}
+
+	b := []byte(code)
+	for i := range b {
+		for c := byte('A'); c <= 'Z'; c++ { // a wrong key
+			if c == code[i] {
+				continue
+			}
+			b[i] = c
+			if !adjacentKeys(code[i], c) {
+				add(b, costWrongKey)
+				continue
+			}
+			add(b, costSlip)
+			for k := i + 1; k < len(b); k++ { // and a second slip
+				for d := byte('A'); d <= 'Z'; d++ {
+					if adjacentKeys(code[k], d) {
+						b[k] = d
+						add(b, 2*costSlip)
+					}
+				}
+				b[k] = code[k]
+			}
+		}
+		b[i] = code[i]
+
+		if i+1 < len(b) && b[i] != b[i+1] { // swapped letters
+			b[i], b[i+1] = b[i+1], b[i]
+			add(b, costSwap)
+			b[i], b[i+1] = b[i+1], b[i]
+		}
+	}
+	return costs
+}
+
+// suggest returns the known codes closest to the code of an unresolved
+// airport token, written like the token. Airports in the country or city of
+// the other airports of the line come first among equally close codes. The
+// codes one typo away are looked up, so it takes as long with any lookup.
+func (p *Prettifier) suggest(tok token, line []*Airport) []string {
+	type candidate struct {
+		code     string
+		cost     float64
+		nearness int
+	}
+	prefix := strings.TrimSuffix(tok.text, tok.arg) // "#", "##", "*#"...
+	var candidates []candidate
+	for code, cost := range typos(tok.arg) {
+		if airport, ok := newToken(prefix+code, 0, 0).airport(p.lookup); ok {
+			candidates = append(candidates, candidate{code, cost, nearness(airport, line)})
+		}
+	}
+
+	sort.Slice(candidates, func(i, j int) bool {
+		a, b := candidates[i], candidates[j]
+		if a.cost != b.cost {
+			return a.cost < b.cost
+		}
+		if a.nearness != b.nearness {
+			return a.nearness > b.nearness
+		}
+		return a.code < b.code
+	})
+	var suggestions []string
+	for i := 0; i < len(candidates) && i < maxSuggestions; i++ {
+		suggestions = append(suggestions, prefix+candidates[i].code)
+	}
+	return suggestions
+}
+
+// nearness tells how close a is to the other airports of a line: 2 for the
+// same city, 1 for the same country
+func nearness(a *Airport, line []*Airport) int {
+	n := 0
+	for _, other := range line {
+		switch {
+		case a.Municipality != "" && a.Municipality == other.Municipality && a.Country == other.Country:
+			n = max(n, 2)
+		case a.Country != "" && a.Country == other.Country:
+			n = max(n, 1)
+		}
+	}
+	return n
+}
+
+// didYouMean writes suggestions as "did you mean #HEL, #HEX or #HLA?"
+func didYouMean(suggestions []string) string {
+	if len(suggestions) == 0 {
+		return ""
+	}
+	last := len(suggestions) - 1
+	if last == 0 {
+		return "did you mean " + suggestions[0] + "?"
+	}
+	return "did you mean " + strings.Join(suggestions[:last], ", ") + " or " + suggestions[last] + "?"
+}
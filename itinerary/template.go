@@ -0,0 +1,106 @@
+package itinerary
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"text/template"
+)
+
+// Flight is a segment of a structured itinerary. Airports are IATA or ICAO
+// codes and times are ISO timestamps like inside T24(...).
+type Flight struct {
+	From      string `json:"from"`
+	To        string `json:"to"`
+	Departure string `json:"departure"`
+	Arrival   string `json:"arrival"`
+	Flight    string `json:"flight"` // flight number
+}
+
+// Itinerary is a structured itinerary, the data of its templates.
+type Itinerary struct {
+	Segments []Flight `json:"segments"`
+}
+
+// ReadItinerary reads a structured itinerary, either {"segments": [...]}
+// or just the list of segments.
+func ReadItinerary(r io.Reader) (*Itinerary, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	it := &Itinerary{}
+	if trimmed := bytes.TrimSpace(data); len(trimmed) > 0 && trimmed[0] == '[' { // only the list
+		err = json.Unmarshal(trimmed, &it.Segments)
+	} else {
+		err = json.Unmarshal(data, it)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrMalformedInput, err)
+	}
+	return it, nil
+}
+
+// Funcs returns the template helpers airport, city, date, time12 and time24.
+// They convert codes and timestamps like the tokens of free text, and return
+// what they can't convert as it is.
+func (p *Prettifier) Funcs() template.FuncMap {
+	return template.FuncMap{
+		"airport": func(code string) string {
+			if a, ok := p.resolve(code); ok {
+				return a.Name
+			}
+			return code
+		},
+		"city": func(code string) string {
+			if a, ok := p.resolve(code); ok {
+				return a.Municipality
+			}
+			return code
+		},
+		"date": func(iso string) string {
+			if _, date, err := formatISODate(iso, p.opts.Locale); err == nil {
+				return date
+			}
+			return iso
+		},
+		"time12": func(iso string) string {
+			if _, formatted, err := formatISOTime(iso, true, p.opts.Locale); err == nil {
+				return formatted
+			}
+			return iso
+		},
+		"time24": func(iso string) string {
+			if _, formatted, err := formatISOTime(iso, false, p.opts.Locale); err == nil {
+				return formatted
+			}
+			return iso
+		},
+	}
+}
+
+// resolve finds an airport by a code of 4 letters as ICAO, otherwise as IATA
+func (p *Prettifier) resolve(code string) (*Airport, bool) {
+	if len(code) == 4 {
+		return p.lookup.ByICAO(code)
+	}
+	return p.lookup.ByIATA(code)
+}
+
+// Template parses text as a template for structured itineraries, with the
+// helpers of Funcs.
+func (p *Prettifier) Template(name, text string) (*template.Template, error) {
+	return template.New(name).Funcs(p.Funcs()).Parse(text)
+}
+
+// ExecuteTemplate reads a structured itinerary from r and writes it to w
+// through tmpl.
+func (p *Prettifier) ExecuteTemplate(tmpl *template.Template, r io.Reader, w io.Writer) error {
+	it, err := ReadItinerary(r)
+	if err != nil {
+		return err
+	}
+	return tmpl.Execute(w, it)
+}
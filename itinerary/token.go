@@ -0,0 +1,164 @@
+package itinerary
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// *# with 3 characters, *## with 4 characters, # and ##, Dates, Times
+const specTokens = `\*\#([A-Z]{3})|\*\##([A-Z]{4})|#([A-Z]{3})|##([A-Z]{4})|D\(([^)]+)\)|T12\(([^)]+)\)|T24\(([^)]+)\)`
+
+var (
+	tokenPattern         = regexp.MustCompile(specTokens)
+	extendedTokenPattern = regexp.MustCompile(specTokens + `|\^\#([A-Z]{3})|\^\##([A-Z]{4})|L12\(([^)]+)\)|L24\(([^)]+)\)|DUR\(([^)]+)\)|DIST\(([^)]+)\)`) // countries, airport local times, durations, distances
+)
+
+// tokenKind tells what a token converts into
+type tokenKind int
+
+const (
+	tokenAirport  tokenKind = iota // #IATA and ##ICAO
+	tokenCity                      // *#IATA and *##ICAO
+	tokenDate                      // D(...)
+	tokenTime12                    // T12(...)
+	tokenTime24                    // T24(...)
+	tokenLocal12                   // L12(..., #IATA) in extended mode
+	tokenLocal24                   // L24(..., #IATA) in extended mode
+	tokenDuration                  // DUR(..., ...) in extended mode
+	tokenCountry                   // ^#IATA and ^##ICAO in extended mode
+	tokenDistance                  // DIST(#IATA, #IATA) in extended mode
+)
+
+var tokenKindNames = [...]string{
+	tokenAirport:  "airport",
+	tokenCity:     "city",
+	tokenDate:     "date",
+	tokenTime12:   "time12",
+	tokenTime24:   "time24",
+	tokenLocal12:  "local12",
+	tokenLocal24:  "local24",
+	tokenDuration: "duration",
+	tokenCountry:  "country",
+	tokenDistance: "distance",
+}
+
+func (k tokenKind) String() string {
+	return tokenKindNames[k]
+}
+
+// token is a code, date or time found in a line
+type token struct {
+	kind  tokenKind
+	start int    // byte offset of the token in the line
+	end   int    // byte offset just after the token
+	text  string // whole token, e.g. "##EDDW" or "T12(2069-04-24T19:18-02:00)"
+	arg   string // airport code or ISO date inside the token
+	icao  bool   // arg is an ICAO code
+}
+
+// scanTokens finds the tokens of a line from left to right. Airport codes
+// glued to letters or digits, like word#AHJ or #AHJ123, are not tokens.
+// The tokens of extended mode are only found when extended is set.
+func scanTokens(line string, extended bool) []token {
+	pattern := tokenPattern
+	if extended {
+		pattern = extendedTokenPattern
+	}
+	var tokens []token
+	for _, m := range pattern.FindAllStringIndex(line, -1) {
+		tok := newToken(line[m[0]:m[1]], m[0], m[1])
+		if (tok.kind == tokenAirport || tok.kind == tokenCity || tok.kind == tokenCountry) && !isSeparated(line, tok.start, tok.end) {
+			continue
+		}
+		tokens = append(tokens, tok)
+	}
+	return tokens
+}
+
+// isSeparated reports whether line[start:end] is not glued to letters or digits
+func isSeparated(line string, start, end int) bool {
+	before, _ := utf8.DecodeLastRuneInString(line[:start])
+	after, _ := utf8.DecodeRuneInString(line[end:])
+	return !isAlnum(before) && !isAlnum(after)
+}
+
+func isAlnum(r rune) bool {
+	return r != utf8.RuneError && (unicode.IsLetter(r) || unicode.IsDigit(r))
+}
+
+func newToken(text string, start, end int) token {
+	tok := token{start: start, end: end, text: text}
+
+	switch {
+	case strings.HasPrefix(text, "*"): // *# or *##
+		tok.kind = tokenCity
+		tok.arg = strings.TrimLeft(text, "*#")
+		tok.icao = strings.HasPrefix(text, "*##")
+
+	case strings.HasPrefix(text, "^"): // ^# or ^##
+		tok.kind = tokenCountry
+		tok.arg = strings.TrimLeft(text, "^#")
+		tok.icao = strings.HasPrefix(text, "^##")
+
+	case strings.HasPrefix(text, "#"): // # or ##
+		tok.kind = tokenAirport
+		tok.arg = strings.TrimLeft(text, "#")
+		tok.icao = strings.HasPrefix(text, "##")
+
+	case strings.HasPrefix(text, "D("):
+		tok.kind = tokenDate
+		tok.arg = text[2 : len(text)-1]
+
+	case strings.HasPrefix(text, "T12("):
+		tok.kind = tokenTime12
+		tok.arg = text[4 : len(text)-1]
+
+	case strings.HasPrefix(text, "T24("):
+		tok.kind = tokenTime24
+		tok.arg = text[4 : len(text)-1]
+
+	case strings.HasPrefix(text, "L12("):
+		tok.kind = tokenLocal12
+		tok.arg = text[4 : len(text)-1]
+
+	case strings.HasPrefix(text, "L24("):
+		tok.kind = tokenLocal24
+		tok.arg = text[4 : len(text)-1]
+
+	case strings.HasPrefix(text, "DIST("):
+		tok.kind = tokenDistance
+		tok.arg = text[5 : len(text)-1]
+
+	case strings.HasPrefix(text, "DUR("):
+		tok.kind = tokenDuration
+		tok.arg = text[4 : len(text)-1]
+	}
+
+	return tok
+}
+
+// airport given inside another token, "#HEL" or "##EFHK"
+var airportRefPattern = regexp.MustCompile(`^(#[A-Z]{3}|##[A-Z]{4})$`)
+
+// airportRef resolves an airport given inside another token
+func (p *Prettifier) airportRef(code string) (*Airport, error) {
+	if !airportRefPattern.MatchString(code) {
+		return nil, fmt.Errorf("want #IATA or ##ICAO, got %q", code)
+	}
+	airport, ok := newToken(code, 0, len(code)).airport(p.lookup)
+	if !ok {
+		return nil, fmt.Errorf("unresolved airport code %s", code)
+	}
+	return airport, nil
+}
+
+// airport resolves the code of an airport or city token
+func (tok token) airport(lookup Lookup) (*Airport, bool) {
+	if tok.icao {
+		return lookup.ByICAO(tok.arg)
+	}
+	return lookup.ByIATA(tok.arg)
+}
@@ -0,0 +1,105 @@
+package itinerary
+
+import (
+	"bufio"
+	"io"
+	"regexp"
+	"strings"
+)
+
+// runs of two and more spaces or tabs
+var spacePattern = regexp.MustCompile(`[ \t]{2,}`)
+
+// \v \f and \r are new lines too
+var lineBreaks = strings.NewReplacer("\f", "\v", "\r", "\v")
+
+// lineWriter renders converted lines while collapsing blank lines. It only
+// remembers how many new lines are pending, so the blank lines are
+// collapsed the same way however the input is split.
+type lineWriter struct {
+	w        *bufio.Writer
+	renderer Renderer
+	newlines int  // new lines not written yet
+	started  bool // a line was written
+}
+
+func newLineWriter(w io.Writer, renderer Renderer) *lineWriter {
+	return &lineWriter{w: bufio.NewWriter(w), renderer: renderer}
+}
+
+// WriteLine writes a converted line followed by a new line
+func (t *lineWriter) WriteLine(segments []segment) error {
+	if len(segments) > 0 { // not blank
+		if block, ok := t.renderer.(BlockRenderer); ok {
+			t.writeBreak(block)
+		} else {
+			t.writeNewlines()
+		}
+		t.started = true
+		if _, err := t.w.WriteString(renderSegments(segments, t.renderer)); err != nil {
+			return err
+		}
+	}
+	t.newlines++
+	return nil
+}
+
+// trimSpaces collapses excessive space. A run of spaces becomes a single
+// space inside the line and is removed at its ends, single spaces are kept.
+// Lines of nothing but space are blank.
+func trimSpaces(line string) string {
+	if strings.TrimSpace(line) == "" {
+		return ""
+	}
+	var result strings.Builder
+	last := 0
+	for _, run := range spacePattern.FindAllStringIndex(line, -1) {
+		result.WriteString(line[last:run[0]])
+		if run[0] > 0 && run[1] < len(line) { // inside the line
+			result.WriteByte(' ')
+		}
+		last = run[1]
+	}
+	result.WriteString(line[last:])
+	return result.String()
+}
+
+// Close writes pending new lines and flushes the output
+func (t *lineWriter) Close() error {
+	if block, ok := t.renderer.(BlockRenderer); ok {
+		if t.started {
+			t.w.WriteString(block.End())
+		}
+	} else {
+		t.writeNewlines()
+	}
+	return t.w.Flush()
+}
+
+// writeBreak starts the document, a new line or a new paragraph.
+// Blank lines before the first and after the last line are dropped.
+func (t *lineWriter) writeBreak(block BlockRenderer) {
+	switch {
+	case !t.started:
+		t.w.WriteString(block.Begin())
+	case t.newlines > 1: // blank lines between
+		t.w.WriteString(block.ParagraphBreak())
+	default:
+		t.w.WriteString(block.LineBreak())
+	}
+	t.newlines = 0
+}
+
+// compiling blank lines to 1 blank line
+func (t *lineWriter) writeNewlines() {
+	if t.newlines > 2 {
+		t.newlines = 2
+	}
+	t.w.WriteString(strings.Repeat("\n", t.newlines)) // errors are kept by bufio until Flush
+	t.newlines = 0
+}
+
+// splitLineBreaks splits a line on \v \f \r
+func splitLineBreaks(line string) []string {
+	return strings.Split(lineBreaks.Replace(line), "\v")
+}
@@ -1,42 +1,109 @@
 package main
 
 import (
-	"bufio"
 	"flag"
 	"fmt"
+	"io"
 	"os"
-	"regexp"
-	"strings"
-	"time"
+	"path/filepath"
+
+	"anyhol/itinerary"
+)
+
+// Modes of the program. Spec mode gives exactly the output of the task,
+// extended mode adds the bonus features.
+const (
+	modeSpec     = "spec"
+	modeExtended = "extended"
 )
 
-var LOOKUPTABLE map[string]string // global variables
-var err error //error
+// renderers of the output file by -format
+var formats = map[string]itinerary.Renderer{
+	"text":     itinerary.PlainRenderer{},
+	"html":     itinerary.HTMLRenderer{},
+	"markdown": itinerary.MarkdownRenderer{},
+}
+
+// formats written as a whole document instead of line by line
+var documentFormats = map[string]func(p *itinerary.Prettifier, r io.Reader, w io.Writer) error{
+	"json": (*itinerary.Prettifier).WriteJSON,
+	"ics":  (*itinerary.Prettifier).WriteICS,
+}
 
 var (
-	helpFlag     bool
-	bonusFlag    bool
-	columnNumber int
+	helpFlag    bool
+	bonusFlag   bool
+	modeFlag    string
+	formatFlag  string
+	tmplFlag    string
+	localeFlag  string
+	codesFlag   bool
+	mdCodesFlag bool
+	daysFlag    bool
+	unitFlag    string
+	configFlag  string
+	columnFlags columnFlag
+	aliasFlags  columnFlag
+	lookupFlags fileFlag
+	provFlag    bool
 )
 
 func init() {
 	flag.BoolVar(&helpFlag, "h", false, "Show help message")
 	flag.BoolVar(&helpFlag, "help", false, "Show help message")
 
-	flag.BoolVar(&bonusFlag, "b", false, "Enable bonus mode")
-	flag.BoolVar(&bonusFlag, "bonus", false, "Enable bonus mode")
+	flag.StringVar(&formatFlag, "format", "text", "Output format: \"text\", \"html\", \"markdown\", \"json\" or \"ics\"")
+	flag.StringVar(&tmplFlag, "template", "", "Read the input as a structured JSON itinerary and write it through this text/template file")
+	flag.StringVar(&localeFlag, "locale", "en", "Language of dates and times, e.g. \"de\" or \"en-US\"")
+	flag.BoolVar(&mdCodesFlag, "markdown-codes", false, "Write airports as \"name (IATA)\" instead of bold with -format markdown")
+	flag.BoolVar(&codesFlag, "country-codes", false, "Write countries (^#IATA, extended mode) as ISO 3166 codes instead of names")
+	flag.BoolVar(&daysFlag, "day-offsets", false, "Mark arrival times on another date than the departure before them with \"+1\", \"+2\" or \"-1\"")
+	flag.StringVar(&unitFlag, "unit", "km", "Unit of distances (DIST, extended mode): \"km\", \"mi\" or \"nm\"")
+	flag.BoolVar(&provFlag, "provenance", false, "Print the lookup file of every converted airport, city and country")
+	addCommonFlags(flag.CommandLine)
 
 	flag.Usage = func() {
-		fmt.Fprintf(os.Stderr, "\033[32mUsage:\033[0m \033[34mgo run . \033[33m[-h help] [-b bonus]\033[0m \033[34m[INPUT FILE] [OUTPUT FILE] [LOOKUP FILE]\033[0m\n")
+		fmt.Fprintf(os.Stderr, "\033[32mitinerary usage:\033[0m \033[34mgo run . \033[33m[-h help] [-b bonus] [-mode spec|extended] [-format text|html|markdown|json|ics] [-template FILE] [-locale en]\033[0m \033[34m[INPUT FILE] [OUTPUT FILE] [LOOKUP FILE]\033[0m\n")
 		fmt.Fprintf(os.Stderr, "\nOptions:\n")
 		flag.VisitAll(func(f *flag.Flag) {
 			fmt.Fprintf(os.Stderr, "  \033[35m-%s, --%s \033[0m- %s\n", f.Name, f.Name, f.Usage)
 		})
 		fmt.Println("  \033[33mEXAMPLE: go run . ./input.txt ./output.txt ./airport-lookup.csv\033[0m")
 		fmt.Println("  \033[33mEXAMPLE: go run . \033[31m-b\033[33m ./input.txt ./output.txt ./airport-lookup.csv\033[0m")
+		fmt.Println("  \033[33mEXAMPLE: go run . check ./input.txt ./airport-lookup.csv\033[0m")
+		fmt.Println("  \033[33mEXAMPLE: go run . lookup compile ./airport-lookup.csv\033[0m")
+		fmt.Println("  \033[33mEXAMPLE: go run . airports search helsinki ./airport-lookup.csv\033[0m")
 	}
 }
 
+// flags shared by all commands
+func addCommonFlags(fs *flag.FlagSet) {
+	mode := os.Getenv("ITINERARY_MODE") // default mode
+	if mode == "" {
+		mode = modeSpec
+	}
+	fs.StringVar(&modeFlag, "mode", mode, "Mode: \"spec\" or \"extended\" (default from $ITINERARY_MODE)")
+	fs.BoolVar(&bonusFlag, "b", false, "Enable bonus mode, same as -mode extended")
+	fs.BoolVar(&bonusFlag, "bonus", false, "Enable bonus mode, same as -mode extended")
+
+	fs.StringVar(&configFlag, "config", "", "JSON config file with lookup column names")
+	fs.Var(&columnFlags, "column", "Lookup column of a field, e.g. iata_code=iata (repeatable)")
+	fs.Var(&aliasFlags, "alias", "Extra lookup column name of a field, e.g. name=airport (repeatable)")
+	fs.Var(&lookupFlags, "lookup", "Overlay lookup applied over the lookup file, later ones win (repeatable)")
+}
+
+// resolveMode turns -mode into bonusFlag
+func resolveMode() error {
+	switch modeFlag {
+	case modeSpec:
+	case modeExtended:
+		bonusFlag = true
+	default:
+		return fmt.Errorf("unknown mode %q, want %q or %q", modeFlag, modeSpec, modeExtended)
+	}
+	return nil
+}
+
 func processPositionalArguments(args []string) {
 	fmt.Printf("\033[32mPositional arguments: %v\n \033[31mWANT: [INPUT] [OUTPUT] [LOOKUP]!\n\033[0m", args)
 }
@@ -44,14 +111,49 @@ func processPositionalArguments(args []string) {
 func main() {
 	if len(os.Args) == 1 {
 		println("itinerary usage:")
-	 	println("go run . ./input.txt ./output.txt ./airport-lookup.csv")
+		println("go run . ./input.txt ./output.txt ./airport-lookup.csv")
+		return
+	}
+	if os.Args[1] == "check" { // linting without output
+		runCheck(os.Args[2:])
+		return
+	}
+	if os.Args[1] == "lookup" { // lookup index
+		runLookup(os.Args[2:])
+		return
+	}
+	if os.Args[1] == "airports" { // searching the lookup
+		runAirports(os.Args[2:])
 		return
 	}
 	flag.Parse()
+	if err := resolveMode(); err != nil {
+		exitWithError("Error:", err)
+	}
+
+	renderer, ok := formats[formatFlag]
+	if _, document := documentFormats[formatFlag]; !ok && !document {
+		exitWithError("Error:", fmt.Errorf("unknown format %q", formatFlag))
+	}
+	if mdCodesFlag && formatFlag != "markdown" {
+		exitWithError("Error:", fmt.Errorf("-markdown-codes needs -format markdown, not %q", formatFlag))
+	} else if mdCodesFlag {
+		renderer = itinerary.MarkdownRenderer{Codes: true}
+	}
+	locale, ok := itinerary.LocaleByName(localeFlag)
+	if !ok {
+		exitWithError("Error:", fmt.Errorf("unknown locale %q", localeFlag))
+	}
+	if !itinerary.DistanceUnit(unitFlag).Valid() {
+		exitWithError("Error:", fmt.Errorf("unknown unit %q", unitFlag))
+	}
+	if tmplFlag != "" && formatFlag != "text" {
+		exitWithError("Error:", fmt.Errorf("-template writes text, it can't be used with -format %s", formatFlag))
+	}
 
-	if helpFlag && !bonusFlag{
+	if helpFlag && !bonusFlag {
 		println("itinerary usage:")
-	 	println("go run . ./input.txt ./output.txt ./airport-lookup.csv")
+		println("go run . ./input.txt ./output.txt ./airport-lookup.csv")
 		return
 	}
 	if helpFlag && bonusFlag {
@@ -65,256 +167,140 @@ func main() {
 		fmt.Println("Normal mode, USE: \"\033[34mgo run . \033[33m-h -b\033[0m\" to see more")
 	}
 
-	 if len(os.Args) < 4 || len(os.Args) > 5 {
-	 	println("itinerary usage:")
-	 	println("go run . ./input.txt ./output.txt ./airport-lookup.csv")
-	 	return
-	 }
+	if len(flag.Args()) != 3 {
+		println("itinerary usage:")
+		println("go run . ./input.txt ./output.txt ./airport-lookup.csv")
+		os.Exit(1)
+	}
 
 	inputPath := flag.Args()[0]
 	outputPath := flag.Args()[1]
 	lookupPath := flag.Args()[2]
 
-	LOOKUPTABLE, err = loadAirportLookup(lookupPath) // loading lookup
-	if err != nil {
-		fmt.Println("Error loading airport lookup:", err) // error
-		return
+	if _, err := os.Stat(inputPath); err != nil { // input is checked before the lookup
+		exitWithError("Error processing itinerary:", fmt.Errorf("%w: %w", itinerary.ErrInputNotFound, err))
 	}
 
-	err = processItinerary(inputPath, outputPath) // converting codes and times
+	columns, err := lookupColumns(configFlag, columnFlags, aliasFlags)
 	if err != nil {
-		fmt.Println("Error processing itinerary:", err)
-		return
+		exitWithError("Error loading airport lookup:", err)
 	}
 
-	println("\033[32mItinerary processed successfully.\033[0m")
-
-}
-
-// loading lookup
-func loadAirportLookup(lookupPath string) (map[string]string, error) {
-	loo, err := os.Open(lookupPath) // open lookup
+	lookup, err := itinerary.LoadLookups(columns, lookupPath, lookupFlags...) // loading lookup with overlays
 	if err != nil {
-		return nil, fmt.Errorf("\033[31mLookup not found\033[0m") // error
+		exitWithError("Error loading airport lookup:", err)
 	}
-	defer loo.Close()
-
-	lookupTable := make(map[string]string) // making a map
-	chuits := 0
-	chuits1 := 2
-	chuits2 := 4
-	chuits3 := 3
-	if bonusFlag {
-		
-		print("enter number of column \"name\", DEFAULT = 0\n")
-		fmt.Scanln(&chuits)
-		print("enter number of column \"city\", DEFAULT = 2\n")
-		fmt.Scanln(&chuits1)
-		print("enter number of column \"IATA code\", DEFAULT = 4\n")
-		fmt.Scanln(&chuits2)
-		print("enter number of column \"ICAO code\", DEFAULT = 3\n")
-		fmt.Scanln(&chuits3)
-	}
-	scanner := bufio.NewScanner(loo)
-	
-	for scanner.Scan() {
-		parts := strings.Split(scanner.Text(), ",") // spliting by ","
-		for i := 0; i < 5; i++ {
-			print(parts[i]," ", *&parts[i], "\n")
-			if parts[i] == "" {
-				return nil, fmt.Errorf("\033[31mMalformed airport lookup data\033[0m") // error
-			}
-		}
-		if len(parts) <= 5 {
-			return nil, fmt.Errorf("\033[31mMalformed airport lookup! data\033[0m") // error
-		}
-
-		name := parts[chuits] 			// 0 chuits
-		municipality := parts[chuits1]  // 2 chuits1
-		iata := parts[chuits2] 			// 4 chuits2
-		icao := parts[chuits3] 			// 3 chuits3
 
-		if iata != "" {
-			lookupTable["*#"+iata] = "\033[34m"+municipality+ "\033[0m" // printing city (municipality)
-		}
-		if icao != "" {
-			lookupTable["*##"+icao] = "\033[34m"+municipality+ "\033[0m" // printing city (municipality)
-		}
-		if iata != "" {
-			lookupTable["#"+iata] = "\033[36m"+name+"\033[0m" // printing name of airport
-		}
-		if icao != "" {
-			lookupTable["##"+icao] = "\033[36m"+name+"\033[0m" // printing name of airport
-		}
+	err = processItinerary(inputPath, outputPath, lookupPath, lookup, renderer, locale) // converting codes and times
+	if err != nil {
+		exitWithError("Error processing itinerary:", err)
 	}
 
-	if err := scanner.Err(); err != nil {
-		return nil, err // error
+	if provFlag {
+		if err := printProvenance(inputPath, lookup); err != nil {
+			exitWithError("Error processing itinerary:", err)
+		}
 	}
 
-	return lookupTable, nil
+	println("\033[32mItinerary processed successfully.\033[0m")
 }
 
-// Working with files
-func processItinerary(inputPath string, outputPath string) error {
-	inputFile, err := os.Open(inputPath) // Opening input
+// printProvenance prints the lookup file of every converted airport, city
+// and country of the input
+func printProvenance(inputPath string, lookup itinerary.Lookup) error {
+	inputFile, err := os.Open(inputPath)
 	if err != nil {
-		return fmt.Errorf("\033[31mInput not found\033[0m") // Error
+		return fmt.Errorf("%w: %w", itinerary.ErrInputNotFound, err)
 	}
 	defer inputFile.Close()
 
-	outputFile, err := os.Create(outputPath) // Creating output
+	d, err := itinerary.New(lookup, itinerary.Options{Extended: bonusFlag, CountryCodes: codesFlag}).Describe(inputFile)
 	if err != nil {
-		return fmt.Errorf("\033[31mError creating output file\033[0m") // Error
-	}
-	defer outputFile.Close()
-
-	scanner := bufio.NewScanner(inputFile) // Reading input
-	if err := scanner.Err(); err != nil {
-		return fmt.Errorf("\033[31mError reading input file\033[0m") // Error
-	}
-
-	var outputTemp string
-
-	for scanner.Scan() { // scanning
-		line := scanner.Text()             // line
-		processedLine := processLine(line) // convering
-		outputTemp += processedLine + "\n" // writing info into a string
+		return err
 	}
-	result := trimLines(outputTemp)  // trimming string
-	if bonusFlag{
-		fmt.Println(result) //
+	for _, tok := range d.Tokens {
+		if tok.Source != "" {
+			fmt.Printf("%s:%d:%d: %s = %s (%s)\n", inputPath, tok.Line, tok.Column, tok.Token, tok.Value, tok.Source)
+		}
 	}
-	result = trimColor(result)
-	fmt.Fprintln(outputFile, result[:len(result)-1]) //Writing string to file
-
 	return nil
 }
 
-func trimColor(text string) string {
-	// text color
-	text = strings.ReplaceAll(text, "\033[0m", "")
-	text = strings.ReplaceAll(text, "\033[30m", "")
-	text = strings.ReplaceAll(text, "\033[34m", "")
-	text = strings.ReplaceAll(text, "\033[35m", "")
-	text = strings.ReplaceAll(text, "\033[36m", "")
-	text = strings.ReplaceAll(text, "\033[37m", "")
-	text = strings.ReplaceAll(text, "\033[31m", "")
-	text = strings.ReplaceAll(text, "\033[32m", "")
-	text = strings.ReplaceAll(text, "\033[33m", "")
-	// fon color
-	text = strings.ReplaceAll(text, "\033[40m", "")
-	text = strings.ReplaceAll(text, "\033[41m", "")
-	text = strings.ReplaceAll(text, "\033[42m", "")
-	text = strings.ReplaceAll(text, "\033[43m", "")
-	text = strings.ReplaceAll(text, "\033[44m", "")
-	text = strings.ReplaceAll(text, "\033[45m", "")
-	text = strings.ReplaceAll(text, "\033[46m", "")
-	text = strings.ReplaceAll(text, "\033[47m", "")
-	// bold
-	text = strings.ReplaceAll(text, "\033[1m", "")
-	text = strings.ReplaceAll(text, "\033[22m", "")
-	return text
+// printing the error in red, colors are never a part of the error itself
+func exitWithError(prefix string, err error) {
+	fmt.Printf("%s \033[31m%s\033[0m\n", prefix, err)
+	os.Exit(1)
 }
 
-// Trim new lines and change \v \r \f to \n
-func trimLines(text string) (result string) {
-	text = strings.ReplaceAll(text, "\v", "\n")
-	text = strings.ReplaceAll(text, "\f", "\n")
-	text = strings.ReplaceAll(text, "\r", "\n")
-	text = strings.ReplaceAll(text, " \n", "\n")
-	
-	pattern := regexp.MustCompile(` \s+`)
-    text = pattern.ReplaceAllString(text, " ")
-
-	regex := regexp.MustCompile(`\n\n+`) // compiling blank lines to 1 blank line // " \s+"
-	result = regex.ReplaceAllString(text, "\n\n")
-	return
-}
+// Working with files
+func processItinerary(inputPath, outputPath, lookupPath string, lookup itinerary.Lookup, renderer itinerary.Renderer, locale *itinerary.Locale) error {
+	if err := itinerary.CheckDistinct(outputPath, append([]string{inputPath, lookupPath}, lookupFlags...)...); err != nil {
+		return err
+	}
 
-// Converting #, ##, * and dates with times
-func processLine(line string) string {
-	// # 3 ch and ## 4 ch, Dates, Times
-	re := regexp.MustCompile(`#([A-Z]{3})|##([A-Z]{4})|D\(([^)]+)\)|T12\(([^)]+)\)|T24\(([^)]+)\)`)
-	if bonusFlag { // if bonus
-		// *# with 3 characters, *## with 4 characters and so on
-		re = regexp.MustCompile(`\*\#([A-Z]{3})|\*\##([A-Z]{4})|#([A-Z]{3})|##([A-Z]{4})|D\(([^)]+)\)|T12\(([^)]+)\)|T24\(([^)]+)\)`)
-	} 
-
-	return re.ReplaceAllStringFunc(line, func(match string) string {
-		switch {
-		case strings.HasPrefix(match, "#") || strings.HasPrefix(match, "##"): // if # or ##
-			if name, exists := LOOKUPTABLE[match]; exists { // returning name of airport
-				return name
-			}
-
-		case strings.HasPrefix(match, "*#") || strings.HasPrefix(match, "*##"): // if *# or *##
-			if municipality, exists := LOOKUPTABLE[match]; exists { // returning city (municipality)
-				return municipality
-			}
-
-		case strings.HasPrefix(match, "D("): // if D(Date)
-			return formatISODate(match) // converting Date from D(YYYY-MM-DDTHH:mmZ) to human readable
-
-		case strings.HasPrefix(match, "T12("): // if T12(Time)
-			return formatISOTime(match[4:len(match)-1], true) // converting Time from T12(YYYY-MM-DDTHH:mmZ) to human readable
-
-		case strings.HasPrefix(match, "T24("): // if T24
-			return formatISOTime(match[4:len(match)-1], false) // converting Time from T24(YYYY-MM-DDTHH:mmZ) to human readable
+	p := itinerary.New(lookup, itinerary.Options{Renderer: renderer, Locale: locale, Extended: bonusFlag, DayOffsets: daysFlag, CountryCodes: codesFlag, Unit: itinerary.DistanceUnit(unitFlag)})
+	if tmplFlag != "" { // structured input
+		if err := itinerary.CheckDistinct(outputPath, tmplFlag); err != nil {
+			return err
 		}
-
-		return match
-	})
-}
-
-// Formatting Date
-func formatISODate(isoDate string) string {
-	parsedTime, err := time.Parse("D(2006-01-02T15:04Z)", isoDate) // if "Z" formatting
-	if err == nil {
-		return "\033[42m\033[1m\033[37m"+parsedTime.Format("02 Jan 2006")+"\033[0m\033[22m"
+		text, err := os.ReadFile(tmplFlag)
+		if err != nil {
+			return fmt.Errorf("template not found: %w", err)
+		}
+		tmpl, err := p.Template(filepath.Base(tmplFlag), string(text))
+		if err != nil {
+			return err
+		}
+		return writeDocument(p, inputPath, outputPath, func(p *itinerary.Prettifier, r io.Reader, w io.Writer) error {
+			return p.ExecuteTemplate(tmpl, r, w)
+		})
 	}
-
-	parsedTimeWithOffset, err := time.Parse("D(2006-01-02T15:04-07:00)", isoDate) // if "02:00" formatting
-	if err != nil {
-		return isoDate
+	if write, ok := documentFormats[formatFlag]; ok {
+		return writeDocument(p, inputPath, outputPath, write)
 	}
 
-	return "\033[42m\033[1m\033[37m"+parsedTimeWithOffset.Format("02 Jan 2006")+"\033[0m\033[22m"
-}
-
-// Formatting Time
-func formatISOTime(isoTime string, is12HourFormat bool) string {
-	var t time.Time
-	var formattedTime string
-	var offset string
-	parts := strings.Split(isoTime, "T") // splitting by "T"
-	if len(parts) != 2 {
-		return isoTime
+	var preview []itinerary.Target
+	if bonusFlag { // colored preview from the same conversion
+		preview = append(preview, itinerary.Target{W: os.Stdout, Renderer: itinerary.ANSIRenderer{}})
 	}
 
-	if strings.HasSuffix(parts[1], "Z") { // if "Z"
-		const customFormat = "2006-01-02T15:04Z"   // format
-		t, err = time.Parse(customFormat, isoTime) // formatting
-	} else {
-		const customFormat = "2006-01-02T15:04-07:00" // if "02:00" format
-		t, err = time.Parse(customFormat, isoTime)    // formatting
+	if err := p.ConvertFile(inputPath, outputPath, preview...); err != nil { // written only on success
+		return err
+	}
+	if bonusFlag {
+		return printWarnings(inputPath, p)
 	}
+	return nil
+}
 
+// printWarnings prints what check would report, e.g. unresolved codes with
+// suggestions, in yellow
+func printWarnings(inputPath string, p *itinerary.Prettifier) error {
+	inputFile, err := os.Open(inputPath)
 	if err != nil {
-		return isoTime
+		return fmt.Errorf("%w: %w", itinerary.ErrInputNotFound, err)
 	}
+	defer inputFile.Close()
 
-	if strings.HasSuffix(parts[1], "Z") { // from "Z" fromatting to "00:00"
-		offset = "(+00:00)"
-	} else {
-		offset = t.Format("(-07:00)") // else formatting
+	problems, err := p.Check(inputFile)
+	if err != nil {
+		return err
 	}
+	for _, problem := range problems {
+		fmt.Printf("\033[33mWarning: %s:%s\033[0m\n", inputPath, problem)
+	}
+	return nil
+}
 
-	if is12HourFormat {
-		formattedTime = t.Format("03:04PM") // if 12H format
-	} else {
-		formattedTime = t.Format("15:04") // if 24H format
+// writeDocument writes a document format of the input, there is no preview
+func writeDocument(p *itinerary.Prettifier, inputPath, outputPath string, write func(p *itinerary.Prettifier, r io.Reader, w io.Writer) error) error {
+	inputFile, err := os.Open(inputPath)
+	if err != nil {
+		return fmt.Errorf("%w: %w", itinerary.ErrInputNotFound, err)
 	}
+	defer inputFile.Close()
 
-	return fmt.Sprintf("\033[40m\033[32m%s %s\033[0m", formattedTime, offset) // printing human readable
+	return itinerary.WriteFileAtomic(outputPath, func(w io.Writer) error { // written only on success
+		return write(p, inputFile, w)
+	})
 }
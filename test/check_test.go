@@ -0,0 +1,53 @@
+package test
+
+import (
+	"os"
+	"strings"
+	"testing"
+)
+
+// TestCheck validates that the check command reports every problem of the
+// input with its line and column, and exits with an error if there are any.
+func TestCheck(t *testing.T) {
+	const input = "From #HIR to #DDD\nD(2024-13-01T08:00Z) T12(1980-02-17T03:30+11:0)\nbell\a ##AGGH T24(2024-02-01T08:00-08:00)"
+
+	expected := []string{
+		":1:14: unresolved airport code #DDD",
+		":2:1: malformed date D(2024-13-01T08:00Z)",
+		":2:22: malformed time12 T12(1980-02-17T03:30+11:0)",
+		":3:5: suspicious control character U+0007",
+	}
+
+	if err := withTempFile2(t.TempDir(), func(inputFile, lookupFile *os.File) {
+		writeAndCloseFile(t, inputFile, input)
+		writeAndCloseFile(t, lookupFile, basicLookup)
+
+		output, err := runUnhandled(t, "check", inputFile.Name(), lookupFile.Name())
+		if err == nil {
+			t.Error("Expected error")
+		}
+		for _, e := range expected {
+			if !strings.Contains(output, inputFile.Name()+e) {
+				t.Errorf("'%s' not found in output:\n%s", e, output)
+			}
+		}
+		if n := strings.Count(output, inputFile.Name()+":"); n != len(expected) {
+			t.Errorf("Expected %d problems, got %d:\n%s", len(expected), n, output)
+		}
+	}); err != nil {
+		t.Fatal("Unexpected error: ", err)
+	}
+}
+
+// TestCheckClean validates that check exits without an error when there
+// are no problems.
+func TestCheckClean(t *testing.T) {
+	if err := withTempFile2(t.TempDir(), func(inputFile, lookupFile *os.File) {
+		writeAndCloseFile(t, inputFile, "From #HIR at T12(2024-02-01T08:00-08:00)\n\tto ##AGGH")
+		writeAndCloseFile(t, lookupFile, basicLookup)
+
+		run(t, "check", inputFile.Name(), lookupFile.Name())
+	}); err != nil {
+		t.Fatal("Unexpected error: ", err)
+	}
+}
@@ -0,0 +1,64 @@
+package test
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"anyhol/itinerary"
+)
+
+// TestCountry validates that ^#IATA and ^##ICAO give the country of the
+// airport, by name or by code.
+func TestCountry(t *testing.T) {
+	p := itinerary.New(testLookup(), itinerary.Options{Extended: true})
+
+	tests := map[string]string{
+		"*#HIR, ^#HIR":               "Honiara, Solomon Islands",
+		"(^##ZUHY)":                  "(China)",
+		"^#XXX word^#HIR":            "^#XXX word^#HIR",
+		"^#HIR^ ^*#HIR^":             "Solomon Islands^ ^Honiara^",
+		"^#HIR D(2024-07-23T15:29Z)": "Solomon Islands 23 Jul 2024",
+	}
+	for input, expected := range tests {
+		if actual := p.Line(input); actual != expected {
+			t.Errorf("%s: expected '%s', got '%s'", input, expected, actual)
+		}
+	}
+
+	codes := itinerary.New(testLookup(), itinerary.Options{Extended: true, CountryCodes: true})
+	if expected, actual := "Honiara, SB", codes.Line("*#HIR, ^#HIR"); actual != expected {
+		t.Errorf("Expected '%s', got '%s'", expected, actual)
+	}
+
+	spec := itinerary.New(testLookup(), itinerary.Options{})
+	if expected, actual := "^Honiara International Airport^", spec.Line("^#HIR^"); actual != expected {
+		t.Errorf("Expected '%s' without extended tokens, got '%s'", expected, actual)
+	}
+}
+
+// TestCountryFlag validates -country-codes and that countries are only
+// converted in extended mode.
+func TestCountryFlag(t *testing.T) {
+	if err := withMockFiles(t.TempDir(), "*#HIR, ^#HIR", basicLookup, func(inputFile, outputFile, lookupFile *os.File) {
+		for _, args := range [][]string{nil, {"-country-codes"}} {
+			run(t, append(args, inputFile.Name(), outputFile.Name(), lookupFile.Name())...)
+			data, err := os.ReadFile(outputFile.Name())
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			expected := "Honiara, ^Honiara International Airport"
+			if mode == "extended" && args == nil {
+				expected = "Honiara, Solomon Islands"
+			} else if mode == "extended" {
+				expected = "Honiara, SB"
+			}
+			if actual := strings.TrimSpace(string(data)); actual != expected {
+				t.Errorf("%v: expected '%s', got '%s'", args, expected, actual)
+			}
+		}
+	}); err != nil {
+		t.Fatal(err)
+	}
+}
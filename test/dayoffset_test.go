@@ -0,0 +1,71 @@
+package test
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"anyhol/itinerary"
+)
+
+// TestDayOffsets validates that arrivals on a later local date than the
+// departure of the line get a day marker.
+func TestDayOffsets(t *testing.T) {
+	p := itinerary.New(testLookup(), itinerary.Options{DayOffsets: true})
+
+	tests := map[string]string{
+		"T24(2024-07-23T22:29Z) - T24(2024-07-24T06:10+02:00)":                                             "22:29 (+00:00) - 06:10 (+02:00) +1",
+		"T12(2024-07-23T15:29-11:00) - T12(2024-07-25T06:10+02:00)":                                        "03:29PM (-11:00) - 06:10AM (+02:00) +2",
+		"T24(2024-07-24T01:00+12:00) - T24(2024-07-23T18:00-10:00)":                                        "01:00 (+12:00) - 18:00 (-10:00) -1",
+		"T24(2024-07-23T08:00Z) - T24(2024-07-23T23:00-05:00)":                                             "08:00 (+00:00) - 23:00 (-05:00)", // next day in UTC only
+		"T24(2024-12-31T22:00Z) T24(2025-01-01T01:00Z) T24(bad)":                                           "22:00 (+00:00) 01:00 (+00:00) +1 T24(bad)",
+		"D(2024-07-23T22:29Z) T24(2024-07-24T06:10Z)":                                                      "23 Jul 2024 06:10 (+00:00)", // dates are not departures
+		"T24(2024-07-23T22:29Z) - T24(2024-07-24T06:10Z), T24(2024-07-24T23:00Z) - T24(2024-07-25T02:00Z)": "22:29 (+00:00) - 06:10 (+00:00) +1, 23:00 (+00:00) - 02:00 (+00:00) +1",
+	}
+	for input, expected := range tests {
+		if actual := p.Line(input); actual != expected {
+			t.Errorf("%s: expected '%s', got '%s'", input, expected, actual)
+		}
+	}
+
+	unrelated := map[string]string{
+		"at T12(2029-09-04T03:09Z) and arrives at T24(2042-09-01T21:43Z)": "at 03:09AM (+00:00) and arrives at 21:43 (+00:00)", // years apart
+		"T24(2024-07-23T08:00Z) - T24(2024-07-26T09:00Z)":                 "08:00 (+00:00) - 09:00 (+00:00)",                   // longer than a flight
+		"T24(2024-07-24T06:10Z) - T24(2024-07-23T22:29Z)":                 "06:10 (+00:00) - 22:29 (+00:00)",                   // arrives before it departs
+	}
+	for input, expected := range unrelated {
+		if actual := p.Line(input); actual != expected {
+			t.Errorf("%s: expected '%s', got '%s'", input, expected, actual)
+		}
+	}
+
+	const input = "T24(2024-07-23T22:29Z)\nT24(2024-07-24T06:10Z)"
+	if expected, actual := "22:29 (+00:00)\n06:10 (+00:00)\n", p.Document(input); actual != expected {
+		t.Errorf("Expected lines to be paired separately, got %q", actual)
+	}
+}
+
+// TestDayOffsetsFlag validates that day markers are only added with
+// -day-offsets, in both modes.
+func TestDayOffsetsFlag(t *testing.T) {
+	const input = "T24(2024-07-23T22:29Z) - T24(2024-07-24T06:10+02:00)"
+	tests := map[string][]string{
+		"22:29 (+00:00) - 06:10 (+02:00)":    nil,
+		"22:29 (+00:00) - 06:10 (+02:00) +1": {"-day-offsets"},
+	}
+
+	for expected, flags := range tests {
+		if err := withMockFiles(t.TempDir(), input, basicLookup, func(inputFile, outputFile, lookupFile *os.File) {
+			run(t, append(flags, inputFile.Name(), outputFile.Name(), lookupFile.Name())...)
+			data, err := os.ReadFile(outputFile.Name())
+			if err != nil {
+				t.Fatal(err)
+			}
+			if actual := strings.TrimSpace(string(data)); actual != expected {
+				t.Errorf("%v: expected '%s', got '%s'", flags, expected, actual)
+			}
+		}); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,68 @@
+package test
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"anyhol/itinerary"
+)
+
+// TestDistance validates the great-circle distance between the coordinates
+// of two airports in every unit.
+func TestDistance(t *testing.T) {
+	tests := []struct {
+		unit     itinerary.DistanceUnit
+		input    string
+		expected string
+	}{
+		{"", "DIST(#HIR,#AHJ)", "7687 km"},
+		{itinerary.Kilometers, "DIST(##AGGH, ##ZUHY)", "7687 km"},
+		{itinerary.Miles, "DIST(#HIR, ##ZUHY)", "4776 mi"},
+		{itinerary.NauticalMiles, "DIST(#HIR,#AHJ)", "4150 nm"},
+		{itinerary.Kilometers, "DIST(#HEL, #HEL)", "0 km"},
+		{itinerary.Kilometers, "DIST(#HEL, #XXX)", "DIST(#HEL, #XXX)"},
+		{itinerary.Kilometers, "DIST(#HEL)", "DIST(#HEL)"},
+		{itinerary.Kilometers, "DIST(HEL, HIR)", "DIST(HEL, HIR)"},
+	}
+	for _, test := range tests {
+		p := itinerary.New(testLookup(), itinerary.Options{Extended: true, Unit: test.unit})
+		if actual := p.Line(test.input); actual != test.expected {
+			t.Errorf("%s in %q: expected '%s', got '%s'", test.input, test.unit, test.expected, actual)
+		}
+	}
+
+	problems, err := itinerary.New(testLookup(), itinerary.Options{Extended: true}).Check(strings.NewReader("DIST(#HEL, #XXX)"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(problems) != 1 || problems[0].Message != "can't convert DIST(#HEL, #XXX): unresolved airport code #XXX" {
+		t.Errorf("Unexpected problems %v", problems)
+	}
+}
+
+// TestDistanceUnitFlag validates -unit, that an unknown unit is an error and
+// that distances are only converted in extended mode.
+func TestDistanceUnitFlag(t *testing.T) {
+	if err := withMockFiles(t.TempDir(), "DIST(#HIR, #AHJ)", basicLookup, func(inputFile, outputFile, lookupFile *os.File) {
+		run(t, "-unit", "mi", inputFile.Name(), outputFile.Name(), lookupFile.Name())
+		data, err := os.ReadFile(outputFile.Name())
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		expected := "DIST(Honiara International Airport, Hongyuan Airport)"
+		if mode == "extended" {
+			expected = "4776 mi"
+		}
+		if actual := strings.TrimSpace(string(data)); actual != expected {
+			t.Errorf("Expected '%s', got '%s'", expected, actual)
+		}
+
+		if _, err := runUnhandled(t, "-unit", "parsec", inputFile.Name(), outputFile.Name(), lookupFile.Name()); err == nil {
+			t.Error("Expected error for unknown unit")
+		}
+	}); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,65 @@
+package test
+
+import (
+	"strings"
+	"testing"
+
+	"anyhol/itinerary"
+)
+
+// TestDuration validates that DUR computes the time between timestamps of
+// different offsets, and that malformed or negative durations are flagged.
+func TestDuration(t *testing.T) {
+	p := itinerary.New(testLookup(), itinerary.Options{Extended: true})
+
+	tests := map[string]string{
+		"DUR(2024-07-23T15:29-11:00, 2024-07-24T06:10+02:00)": "1h 41m",
+		"DUR(2024-07-23T15:29Z,2024-07-24T06:10Z)":            "14h 41m",
+		"DUR(2024-07-23T15:29Z, 2024-07-23T15:29Z)":           "0h 0m",
+		"DUR(2024-07-24T06:10Z, 2024-07-23T15:29Z)":           "DUR(2024-07-24T06:10Z, 2024-07-23T15:29Z) [negative duration]",
+		"DUR(2024-07-23T15:29Z, bad)":                         "DUR(2024-07-23T15:29Z, bad) [malformed time bad]",
+		"DUR(2024-07-23T15:29Z)":                              "DUR(2024-07-23T15:29Z) [want DUR(departure, arrival)]",
+	}
+	for input, expected := range tests {
+		if actual := p.Line(input); actual != expected {
+			t.Errorf("%s: expected '%s', got '%s'", input, expected, actual)
+		}
+	}
+
+	const input = "DUR(2024-07-23T15:29Z, 2024-07-24T06:10Z)"
+	if expected, actual := `<time class="duration" datetime="PT14H41M">14h 41m</time>`, itinerary.New(testLookup(), itinerary.Options{Extended: true, Renderer: itinerary.HTMLRenderer{}}).Line(input); actual != expected {
+		t.Errorf("Expected '%s', got '%s'", expected, actual)
+	}
+	if actual := itinerary.New(testLookup(), itinerary.Options{}).Line(input); actual != input {
+		t.Errorf("Expected '%s' without extended tokens, got '%s'", input, actual)
+	}
+}
+
+// TestDurationProblems validates that flagged durations are reported by
+// Check and described in JSON.
+func TestDurationProblems(t *testing.T) {
+	p := itinerary.New(testLookup(), itinerary.Options{Extended: true})
+	const input = "DUR(2024-07-24T06:10Z, 2024-07-23T15:29Z) DUR(2024-07-23T15:29Z, 2024-07-24T06:10Z)"
+
+	problems, err := p.Check(strings.NewReader(input))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(problems) != 1 || problems[0].String() != "1:1: can't convert DUR(2024-07-24T06:10Z, 2024-07-23T15:29Z): negative duration" {
+		t.Errorf("Unexpected problems %v", problems)
+	}
+
+	d, err := p.Describe(strings.NewReader(input))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(d.Tokens) != 2 {
+		t.Fatalf("Expected 2 tokens, got %+v", d.Tokens)
+	}
+	if tok := d.Tokens[0]; tok.Resolved || tok.Error != "negative duration" {
+		t.Errorf("Expected flagged token, got %+v", tok)
+	}
+	if tok := d.Tokens[1]; !tok.Resolved || tok.Kind != "duration" || tok.Value != "14h 41m" || tok.Duration != "PT14H41M" {
+		t.Errorf("Unexpected token %+v", tok)
+	}
+}
@@ -459,17 +459,88 @@ func TestOutputIsNotCreatedOnError(t *testing.T) {
 // The program is expected to not create nor alter the content of the output file
 // if any error occurs during execution.
 func TestOutputIsNotAlteredOnError(t *testing.T) {
+	const existing = "Existing output"
+
+	cases := []struct {
+		name         string
+		lookup       string
+		removeInput  bool
+		removeLookup bool
+	}{
+		{"InputNotExist", basicLookup, true, false},
+		{"LookupNotExist", basicLookup, false, true},
+		{"MalformedLookup", "name,iso_country" + lookupBasicBody, false, false},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if err := withMockFiles(t.TempDir(), "#HIR", c.lookup, func(inputFile, outputFile, lookupFile *os.File) {
+				if err := os.WriteFile(outputFile.Name(), []byte(existing), 0o644); err != nil {
+					t.Fatal(err)
+				}
+				if c.removeInput {
+					removeFile(inputFile)
+				}
+				if c.removeLookup {
+					removeFile(lookupFile)
+				}
+
+				if _, err := runUnhandled(t, inputFile.Name(), outputFile.Name(), lookupFile.Name()); err == nil {
+					t.Error("Expected error")
+				}
 
+				if data, err := os.ReadFile(outputFile.Name()); err != nil {
+					t.Fatal("Output file was removed: ", err)
+				} else if string(data) != existing {
+					t.Fatalf("Output file was altered: '%s'", string(data))
+				}
+			}); err != nil {
+				t.Fatal("Unexpected error: ", err)
+			}
+		})
+	}
 }
 
 // TestInputIsNotAltered validates that the input file is not altered.
 // The program is expected to never alter the input file.
 func TestInputIsNotAltered(t *testing.T) {
+	const input = "#HIR"
+
+	if err := withMockFiles(t.TempDir(), input, basicLookup, func(inputFile, outputFile, lookupFile *os.File) {
+		// Input given as output too
+		if _, err := runUnhandled(t, inputFile.Name(), inputFile.Name(), lookupFile.Name()); err == nil {
+			t.Error("Expected error when output is the input file")
+		}
 
+		run(t, inputFile.Name(), outputFile.Name(), lookupFile.Name())
+
+		if data, err := os.ReadFile(inputFile.Name()); err != nil {
+			t.Fatal(err)
+		} else if string(data) != input {
+			t.Fatalf("Input file was altered: '%s'", string(data))
+		}
+	}); err != nil {
+		t.Fatal("Unexpected error: ", err)
+	}
 }
 
 // TestLookupIsNotAltered validates that the lookup file is not altered.
 // The program is expected to never alter the input file.
 func TestLookupIsNotAltered(t *testing.T) {
+	if err := withMockFiles(t.TempDir(), "#HIR", basicLookup, func(inputFile, outputFile, lookupFile *os.File) {
+		// Lookup given as output too
+		if _, err := runUnhandled(t, inputFile.Name(), lookupFile.Name(), lookupFile.Name()); err == nil {
+			t.Error("Expected error when output is the lookup file")
+		}
 
+		run(t, inputFile.Name(), outputFile.Name(), lookupFile.Name())
+
+		if data, err := os.ReadFile(lookupFile.Name()); err != nil {
+			t.Fatal(err)
+		} else if string(data) != basicLookup {
+			t.Fatalf("Lookup file was altered: '%s'", string(data))
+		}
+	}); err != nil {
+		t.Fatal("Unexpected error: ", err)
+	}
 }
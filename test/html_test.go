@@ -0,0 +1,63 @@
+package test
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"anyhol/itinerary"
+)
+
+// TestHTMLRenderer validates that text is escaped, converted values are
+// wrapped in elements and blank lines become paragraphs.
+func TestHTMLRenderer(t *testing.T) {
+	p := itinerary.New(testLookup(), itinerary.Options{Renderer: itinerary.HTMLRenderer{}})
+
+	const input = "\n\nFrom #HIR <to> *##ZUHY & #XXX\non D(2022-05-09T08:07Z)\n\n\n\nat T24(2024-07-23T15:29-11:00)\n\n"
+	const expected = "<div class=\"itinerary\">\n" +
+		"<p>From <span class=\"airport\" data-iata=\"HIR\" data-icao=\"AGGH\">Honiara International Airport</span> &lt;to&gt; <span class=\"city\">Aba</span> &amp; #XXX<br>\n" +
+		"on <time class=\"date\" datetime=\"2022-05-09\">09 May 2022</time></p>\n" +
+		"<p>at <time class=\"time\" datetime=\"2024-07-23T15:29:00-11:00\">15:29 (-11:00)</time></p>\n" +
+		"</div>\n"
+
+	if actual := p.Document(input); actual != expected {
+		t.Errorf("Expected %q, got %q", expected, actual)
+	}
+	if actual := p.Document("\n\n"); actual != "" {
+		t.Errorf("Expected no output for blank input, got %q", actual)
+	}
+}
+
+// TestFormatHTML validates that -format html writes the output file as HTML.
+func TestFormatHTML(t *testing.T) {
+	if err := withMockFiles(t.TempDir(), "From #HIR\n\n\nto #AGGH", basicLookup, func(inputFile, outputFile, lookupFile *os.File) {
+		run(t, "-format", "html", inputFile.Name(), outputFile.Name(), lookupFile.Name())
+
+		data, err := os.ReadFile(outputFile.Name())
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, expected := range []string{`<div class="itinerary">`, `<p>From <span class="airport"`, "</p>\n<p>to "} {
+			if !strings.Contains(string(data), expected) {
+				t.Errorf("'%s' not found in output:\n%s", expected, data)
+			}
+		}
+	}); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// TestFormatUnknown validates that an unknown format is an error.
+func TestFormatUnknown(t *testing.T) {
+	if err := withMockFiles(t.TempDir(), "#HIR", basicLookup, func(inputFile, outputFile, lookupFile *os.File) {
+		output, err := runUnhandled(t, "-format", "pdf", inputFile.Name(), outputFile.Name(), lookupFile.Name())
+		if err == nil {
+			t.Error("Expected error")
+		}
+		if !strings.Contains(output, "unknown format") {
+			t.Errorf("Expected unknown format error, got:\n%s", output)
+		}
+	}); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,65 @@
+package test
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"anyhol/itinerary"
+)
+
+// TestWriteICS validates that lines with times become events with their
+// times in UTC, the first airport as location and the converted line as
+// summary, that long lines are folded and that lines without times are
+// left out.
+func TestWriteICS(t *testing.T) {
+	p := itinerary.New(testLookup(), itinerary.Options{})
+
+	const input = "Trip, part 1\n#HIR to #AHJ T24(2024-07-23T15:29-11:00) - T12(2024-07-24T18:00+08:00)\n\nBack D(2024-08-01T10:00Z) T24(2024-08-01T10:00Z)"
+	var out strings.Builder
+	if err := p.WriteICS(strings.NewReader(input), &out); err != nil {
+		t.Fatal(err)
+	}
+	ics := strings.ReplaceAll(out.String(), "\r\n ", "") // unfolding
+	for _, line := range strings.Split(out.String(), "\r\n") {
+		if len(line) > 75 {
+			t.Errorf("Line longer than 75 bytes: %q", line)
+		}
+	}
+
+	for _, expected := range []string{
+		"BEGIN:VCALENDAR\r\nVERSION:2.0\r\n",
+		"DTSTART:20240724T022900Z\r\nDTEND:20240724T100000Z\r\n",
+		"LOCATION:Honiara International Airport\r\n",
+		"SUMMARY:Honiara International Airport to Hongyuan Airport 15:29 (-11:00) - 06:00PM (+08:00)\r\n",
+		"DTSTART:20240801T100000Z\r\nSUMMARY:Back 01 Aug 2024 10:00 (+00:00)\r\nEND:VEVENT\r\n",
+		"END:VCALENDAR\r\n",
+	} {
+		if !strings.Contains(ics, expected) {
+			t.Errorf("%q not found in:\n%s", expected, ics)
+		}
+	}
+	if n := strings.Count(ics, "BEGIN:VEVENT"); n != 2 {
+		t.Errorf("Expected 2 events, got %d:\n%s", n, ics)
+	}
+	if strings.Contains(ics, "Trip") {
+		t.Errorf("Line without times became an event:\n%s", ics)
+	}
+}
+
+// TestFormatICS validates that -format ics writes an iCalendar.
+func TestFormatICS(t *testing.T) {
+	if err := withMockFiles(t.TempDir(), "#HIR T24(2024-07-23T15:29-11:00)", basicLookup, func(inputFile, outputFile, lookupFile *os.File) {
+		run(t, "-format", "ics", inputFile.Name(), outputFile.Name(), lookupFile.Name())
+
+		data, err := os.ReadFile(outputFile.Name())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !strings.Contains(string(data), "BEGIN:VEVENT\r\n") || !strings.Contains(string(data), "DTSTART:20240724T022900Z\r\n") {
+			t.Errorf("Unexpected output:\n%s", data)
+		}
+	}); err != nil {
+		t.Fatal(err)
+	}
+}
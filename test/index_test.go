@@ -0,0 +1,244 @@
+package test
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"hash/crc32"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"anyhol/itinerary"
+)
+
+// TestLookupIndex validates that a compiled lookup loads the same airports,
+// that a stale index is rebuilt when the CSV changes and that it is only
+// used with the columns it was compiled with.
+func TestLookupIndex(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "lookup.csv")
+	data, err := os.ReadFile("test/input/lookup.csv")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	compiled, err := itinerary.CompileLookup(path, itinerary.DefaultColumns())
+	if err != nil {
+		t.Fatal(err)
+	}
+	index, err := os.ReadFile(itinerary.IndexPath(path))
+	if err != nil {
+		t.Fatalf("Index not written: %s", err)
+	}
+
+	loaded, err := itinerary.LoadLookup(path, itinerary.DefaultColumns())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(compiled.Airports(), loaded.Airports()) {
+		t.Errorf("Indexed lookup differs:\n%+v\n%+v", compiled.Airports(), loaded.Airports())
+	}
+
+	changed := strings.Replace(string(data), "Honiara International Airport", "Honiara Airport", 1)
+	if err := os.WriteFile(path, []byte(changed), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err = itinerary.LoadLookup(path, itinerary.DefaultColumns())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if a, ok := loaded.ByIATA("HIR"); !ok || a.Name != "Honiara Airport" {
+		t.Errorf("Stale index used: %+v", a)
+	}
+	if rebuilt, err := os.ReadFile(itinerary.IndexPath(path)); err != nil || bytes.Equal(rebuilt, index) {
+		t.Errorf("Stale index not rebuilt: %v", err)
+	}
+
+	cols := itinerary.DefaultColumns()
+	if err := cols.Override(itinerary.FieldName, "municipality"); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err = itinerary.LoadLookup(path, cols)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if a, ok := loaded.ByIATA("HIR"); !ok || a.Name != "Honiara" {
+		t.Errorf("Index used with other columns: %+v", a)
+	}
+}
+
+// TestLookupIndexKeys validates that a compiled lookup finds the same
+// airport for every code as the CSV, also when rows share an IATA code.
+func TestLookupIndexKeys(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "lookup.csv")
+	if err := os.WriteFile(path, []byte(largeLookup(20000)), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	parsed, err := itinerary.LoadLookup(path, itinerary.DefaultColumns())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := itinerary.CompileLookup(path, itinerary.DefaultColumns()); err != nil {
+		t.Fatal(err)
+	}
+	indexed, err := itinerary.LoadLookup(path, itinerary.DefaultColumns())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, a := range parsed.Airports() {
+		for _, find := range []func(l *itinerary.MapLookup) (*itinerary.Airport, bool){
+			func(l *itinerary.MapLookup) (*itinerary.Airport, bool) { return l.ByIATA(a.IATA) },
+			func(l *itinerary.MapLookup) (*itinerary.Airport, bool) { return l.ByICAO(a.ICAO) },
+		} {
+			expected, _ := find(parsed)
+			actual, ok := find(indexed)
+			if !ok || !sameAirport(expected, actual) {
+				t.Fatalf("Expected %+v, got %+v", expected, actual)
+			}
+		}
+	}
+	if _, ok := indexed.ByIATA("AAA"); !ok {
+		t.Error("Expected the shadowed code AAA to be found")
+	}
+	if _, ok := indexed.ByICAO("ZZZZ"); ok {
+		t.Error("Unexpected airport ZZZZ")
+	}
+
+	a, _ := indexed.ByIATA("BCD")
+	indexed.Remove(a) // changes work on compiled lookups too
+	if _, ok := indexed.ByIATA("BCD"); ok {
+		t.Error("Removed airport found")
+	}
+	if b, ok := indexed.ByIATA("BCE"); !ok || b.IATA != "BCE" {
+		t.Errorf("Unexpected airport %+v", b)
+	}
+}
+
+// sameAirport compares the fields a compiled lookup keeps, extra columns
+// are not kept
+func sameAirport(a, b *itinerary.Airport) bool {
+	return a.Name == b.Name && a.Country == b.Country && a.Municipality == b.Municipality && a.ICAO == b.ICAO && a.IATA == b.IATA &&
+		a.Latitude == b.Latitude && a.Longitude == b.Longitude && a.Timezone == b.Timezone && a.Source == b.Source
+}
+
+// TestLookupIndexDamaged validates that a damaged index never breaks
+// loading, the lookup falls back to the CSV. Bytes are flipped with and
+// without a matching checksum, so the decoder meets the damage too, and
+// huge lengths are written over them.
+func TestLookupIndexDamaged(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "lookup.csv")
+	if err := os.WriteFile(path, []byte(basicLookup), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := itinerary.CompileLookup(path, itinerary.DefaultColumns()); err != nil {
+		t.Fatal(err)
+	}
+	index, err := os.ReadFile(itinerary.IndexPath(path))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	huge := binary.AppendUvarint(nil, 1<<63+200) // negative as an int
+	for i := 0; i < len(index); i++ {
+		for _, resum := range []bool{false, true} {
+			damaged := bytes.Clone(index)
+			damaged[i] ^= 0xff
+			if i%2 == 1 && i+len(huge) < len(damaged)-4 {
+				copy(damaged[i:], huge)
+			}
+			if resum && i < len(damaged)-4 {
+				body := damaged[:len(damaged)-4]
+				damaged = binary.LittleEndian.AppendUint32(body, crc32.Checksum(body, crc32.MakeTable(crc32.Castagnoli)))
+			}
+			if err := os.WriteFile(itinerary.IndexPath(path), damaged, 0o644); err != nil {
+				t.Fatal(err)
+			}
+
+			lookup, err := itinerary.LoadLookup(path, itinerary.DefaultColumns())
+			if err != nil {
+				t.Fatalf("Byte %d flipped: %s", i, err)
+			}
+			if a, ok := lookup.ByIATA("HIR"); !resum && (!ok || a.Name != "Honiara International Airport") {
+				t.Fatalf("Byte %d flipped: damaged index used: %+v", i, a)
+			}
+			lookup.Airports() // decodes every airport
+		}
+	}
+}
+
+// TestLookupCompileCommand validates that lookup compile writes the index
+// and that conversion works with it.
+func TestLookupCompileCommand(t *testing.T) {
+	if err := withMockFiles(t.TempDir(), "#HIR *##AGGH", basicLookup, func(inputFile, outputFile, lookupFile *os.File) {
+		defer os.Remove(itinerary.IndexPath(lookupFile.Name()))
+
+		run(t, "lookup", "compile", lookupFile.Name())
+		if _, err := os.Stat(itinerary.IndexPath(lookupFile.Name())); err != nil {
+			t.Fatalf("Index not written: %s", err)
+		}
+
+		run(t, inputFile.Name(), outputFile.Name(), lookupFile.Name())
+		data, err := os.ReadFile(outputFile.Name())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if expected, actual := "Honiara International Airport Honiara", strings.TrimSpace(string(data)); actual != expected {
+			t.Errorf("Expected '%s', got '%s'", expected, actual)
+		}
+	}); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// BenchmarkLoadLookup compares loading a lookup of 80k airports from its CSV
+// and from its compiled index.
+func BenchmarkLoadLookup(b *testing.B) {
+	path := filepath.Join(b.TempDir(), "lookup.csv")
+	if err := os.WriteFile(path, []byte(largeLookup(80000)), 0o644); err != nil {
+		b.Fatal(err)
+	}
+
+	b.Run("csv", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			if _, err := itinerary.LoadLookup(path, itinerary.DefaultColumns()); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+
+	if _, err := itinerary.CompileLookup(path, itinerary.DefaultColumns()); err != nil {
+		b.Fatal(err)
+	}
+	csvInfo, _ := os.Stat(path)
+	indexInfo, _ := os.Stat(itinerary.IndexPath(path))
+	b.Run("index", func(b *testing.B) {
+		b.ReportMetric(float64(indexInfo.Size())/float64(csvInfo.Size()), "size/csv")
+		for i := 0; i < b.N; i++ {
+			if _, err := itinerary.LoadLookup(path, itinerary.DefaultColumns()); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
+
+// largeLookup returns a lookup CSV of n made up airports with the columns of
+// a public airport dataset
+func largeLookup(n int) string {
+	var csv strings.Builder
+	csv.WriteString("id,ident,type,name,iso_country,iso_region,municipality,icao_code,iata_code,gps_code,local_code,elevation_ft,coordinates,tz\n")
+	countries := []string{"FI", "SB", "CN", "US", "DE", "BR", "AU"}
+	for i := 0; i < n; i++ {
+		icao := string([]byte{byte('A' + i/17576%26), byte('A' + i/676%26), byte('A' + i/26%26), byte('A' + i%26)})
+		country := countries[i%len(countries)]
+		fmt.Fprintf(&csv, "%d,%s,small_airport,%s Municipal Airport %d,%s,%s-%02d,Town %d,%s,%s,%s,%s,%d,\"%.10f, %.10f\",Europe/Helsinki\n",
+			i, icao, icao, i, country, country, i%50, i%5000, icao, icao[1:], icao, icao[1:], i%3000, float64(i%360)-180+0.123456789, float64(i%180)-90+0.987654321)
+	}
+	return csv.String()
+}
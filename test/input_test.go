@@ -173,6 +173,9 @@ func TestAiportCodesSurroundedWithWordBreak(t *testing.T) {
 
 	for _, c := range cases {
 		name, input, expected := c[0], c[1], c[2]
+		if mode == "extended" && name == "caret ^airport^" { // ^# and ^## are countries in extended mode
+			expected = `Solomon Islands^ Solomon Islands^`
+		}
 		t.Run(name, func(t *testing.T) {
 			runWithMockFiles(t, input, basicLookup, expected, false, 5)
 		})
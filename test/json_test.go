@@ -0,0 +1,107 @@
+package test
+
+import (
+	"encoding/json"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"anyhol/itinerary"
+)
+
+// TestDescribe validates the token records: position, resolution and the
+// converted value or timestamp.
+func TestDescribe(t *testing.T) {
+	p := itinerary.New(testLookup(), itinerary.Options{})
+
+	const input = "From ##AGGH\r\nto #XXX at T12(2069-04-24T19:18-02:00), ä *#AHJ D(bad)"
+	d, err := p.Describe(strings.NewReader(input))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if expected := "From Honiara International Airport\nto #XXX at 07:18PM (-02:00), ä Aba D(bad)\n"; d.Text != expected {
+		t.Errorf("Expected text %q, got %q", expected, d.Text)
+	}
+
+	stamp := time.Date(2069, 4, 24, 19, 18, 0, 0, time.FixedZone("", -2*60*60))
+	expected := []itinerary.Token{
+		{Token: "##AGGH", Kind: "airport", Offset: 5, Line: 1, Column: 6, Resolved: true, Value: "Honiara International Airport"},
+		{Token: "#XXX", Kind: "airport", Offset: 16, Line: 2, Column: 4},
+		{Token: "T12(2069-04-24T19:18-02:00)", Kind: "time12", Offset: 24, Line: 2, Column: 12, Resolved: true, Value: "07:18PM (-02:00)", Time: &stamp},
+		{Token: "*#AHJ", Kind: "city", Offset: 56, Line: 2, Column: 43, Resolved: true, Value: "Aba"},
+		{Token: "D(bad)", Kind: "date", Offset: 62, Line: 2, Column: 49},
+	}
+	if len(d.Tokens) != len(expected) {
+		t.Fatalf("Expected %d tokens, got %d: %+v", len(expected), len(d.Tokens), d.Tokens)
+	}
+	for i, e := range expected {
+		a := d.Tokens[i]
+		if a.Token != e.Token || a.Kind != e.Kind || a.Offset != e.Offset || a.Line != e.Line || a.Column != e.Column || a.Resolved != e.Resolved || a.Value != e.Value {
+			t.Errorf("Expected token %+v, got %+v", e, a)
+		}
+		if (a.Time == nil) != (e.Time == nil) || a.Time != nil && !a.Time.Equal(*e.Time) {
+			t.Errorf("Expected time %v of %s, got %v", e.Time, e.Token, a.Time)
+		}
+		if hasAirport := e.Resolved && (e.Kind == "airport" || e.Kind == "city"); (a.Airport != nil) != hasAirport {
+			t.Errorf("Unexpected airport %+v of %s", a.Airport, e.Token)
+		}
+	}
+}
+
+// TestDescribeDayOffsets validates that token values agree with the text,
+// day markers included, also across \v line breaks.
+func TestDescribeDayOffsets(t *testing.T) {
+	p := itinerary.New(testLookup(), itinerary.Options{DayOffsets: true})
+
+	const input = "T24(2024-07-23T22:29Z)\vT24(2024-07-23T23:00Z) - T24(2024-07-24T06:10+02:00)"
+	d, err := p.Describe(strings.NewReader(input))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if expected := "22:29 (+00:00)\n23:00 (+00:00) - 06:10 (+02:00) +1\n"; d.Text != expected {
+		t.Errorf("Expected text %q, got %q", expected, d.Text)
+	}
+	expected := []itinerary.Token{
+		{Value: "22:29 (+00:00)", Offset: 0, Column: 1},
+		{Value: "23:00 (+00:00)", Offset: 23, Column: 24},
+		{Value: "06:10 (+02:00) +1", Offset: 48, Column: 49},
+	}
+	if len(d.Tokens) != len(expected) {
+		t.Fatalf("Expected %d tokens, got %d: %+v", len(expected), len(d.Tokens), d.Tokens)
+	}
+	for i, e := range expected {
+		if a := d.Tokens[i]; a.Value != e.Value || a.Offset != e.Offset || a.Column != e.Column {
+			t.Errorf("Expected token %+v, got %+v", e, a)
+		}
+	}
+}
+
+// TestFormatJSON validates that -format json writes the text and tokens.
+func TestFormatJSON(t *testing.T) {
+	if err := withMockFiles(t.TempDir(), "From #HIR at T24(2024-07-23T15:29-11:00)", basicLookup, func(inputFile, outputFile, lookupFile *os.File) {
+		run(t, "-format", "json", inputFile.Name(), outputFile.Name(), lookupFile.Name())
+
+		data, err := os.ReadFile(outputFile.Name())
+		if err != nil {
+			t.Fatal(err)
+		}
+		var d struct {
+			Text   string
+			Tokens []map[string]any
+		}
+		if err := json.Unmarshal(data, &d); err != nil {
+			t.Fatalf("Malformed JSON: %s\n%s", err, data)
+		}
+		if expected := "From Honiara International Airport at 15:29 (-11:00)\n"; d.Text != expected {
+			t.Errorf("Expected text %q, got %q", expected, d.Text)
+		}
+		if len(d.Tokens) != 2 || d.Tokens[0]["token"] != "#HIR" || d.Tokens[1]["time"] != "2024-07-23T15:29:00-11:00" {
+			t.Errorf("Unexpected tokens %v", d.Tokens)
+		}
+	}); err != nil {
+		t.Fatal(err)
+	}
+}
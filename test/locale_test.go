@@ -0,0 +1,78 @@
+package test
+
+import (
+	"os"
+	"testing"
+
+	"anyhol/itinerary"
+)
+
+// TestLocales validates the month names, date order and 12/24 hour
+// conventions of the built in locales.
+func TestLocales(t *testing.T) {
+	const input = "D(2022-03-09T08:07Z) T12(2069-04-24T19:18-02:00) T24(2069-04-24T07:18-02:00)"
+
+	tests := map[string]string{
+		"en":    "09 Mar 2022 07:18PM (-02:00) 07:18 (-02:00)",
+		"en-US": "Mar 09, 2022 07:18PM (-02:00) 07:18 (-02:00)",
+		"de":    "9. März 2022 19:18 (-02:00) 07:18 (-02:00)",
+		"de_AT": "9. März 2022 19:18 (-02:00) 07:18 (-02:00)",
+		"fr":    "9 mars 2022 19:18 (-02:00) 07:18 (-02:00)",
+		"es":    "9 mar 2022 07:18 p. m. (-02:00) 07:18 (-02:00)",
+		"ja":    "2022年3月9日 19:18 (-02:00) 07:18 (-02:00)",
+	}
+	for name, expected := range tests {
+		locale, ok := itinerary.LocaleByName(name)
+		if !ok {
+			t.Errorf("Locale %s not found", name)
+			continue
+		}
+		p := itinerary.New(testLookup(), itinerary.Options{Locale: locale})
+		if actual := p.Line(input); actual != expected {
+			t.Errorf("%s: expected '%s', got '%s'", name, expected, actual)
+		}
+	}
+
+	if _, ok := itinerary.LocaleByName("xx"); ok {
+		t.Error("Expected unknown locale")
+	}
+}
+
+// TestRegisterLocale validates that locales can be added.
+func TestRegisterLocale(t *testing.T) {
+	nl := itinerary.English
+	nl.Months = [12]string{"jan", "feb", "mrt", "apr", "mei", "jun", "jul", "aug", "sep", "okt", "nov", "dec"}
+	nl.Weekdays = [7]string{"zo", "ma", "di", "wo", "do", "vr", "za"}
+	nl.Date = "Mon 2 Jan 2006"
+	nl.Hour24 = true
+	itinerary.RegisterLocale("nl", nl)
+
+	locale, ok := itinerary.LocaleByName("nl-BE")
+	if !ok {
+		t.Fatal("Registered locale not found")
+	}
+	p := itinerary.New(testLookup(), itinerary.Options{Locale: locale})
+	if expected, actual := "wo 9 mei 2029 19:18 (+00:00)", p.Line("D(2029-05-09T08:07Z) T12(2029-05-09T19:18Z)"); actual != expected {
+		t.Errorf("Expected '%s', got '%s'", expected, actual)
+	}
+}
+
+// TestLocaleFlag validates -locale and that an unknown locale is an error.
+func TestLocaleFlag(t *testing.T) {
+	if err := withMockFiles(t.TempDir(), "D(2022-03-09T08:07Z)", basicLookup, func(inputFile, outputFile, lookupFile *os.File) {
+		run(t, "-locale", "de", inputFile.Name(), outputFile.Name(), lookupFile.Name())
+		data, err := os.ReadFile(outputFile.Name())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if expected := "9. März 2022\n"; string(data) != expected {
+			t.Errorf("Expected %q, got %q", expected, data)
+		}
+
+		if _, err := runUnhandled(t, "-locale", "xx", inputFile.Name(), outputFile.Name(), lookupFile.Name()); err == nil {
+			t.Error("Expected error for unknown locale")
+		}
+	}); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,87 @@
+package test
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"anyhol/itinerary"
+)
+
+// TestLocalTime validates that L12 and L24 convert the instant into the time
+// zone of the airport, daylight saving time included.
+func TestLocalTime(t *testing.T) {
+	p := itinerary.New(testLookup(), itinerary.Options{Extended: true})
+
+	tests := map[string]string{
+		"L24(2024-07-23T15:29Z, #HEL)":         "18:29 (+03:00)",
+		"L24(2024-01-23T15:29Z, ##EFHK)":       "17:29 (+02:00)",
+		"L12(2024-07-23T15:29-11:00, #HEL)":    "05:29AM (+03:00)",
+		"L24(2024-07-23T15:29Z,#HIR)":          "02:29 (+11:00)",
+		"L24(2024-07-23T15:29Z, #AHJ)":         "L24(2024-07-23T15:29Z, #AHJ)", // no time zone
+		"L24(2024-07-23T15:29Z, #XXX)":         "L24(2024-07-23T15:29Z, #XXX)",
+		"L24(2024-07-23T15:29Z)":               "L24(2024-07-23T15:29Z)",
+		"L24(2024-07-23 15:29, #HEL)":          "L24(2024-07-23 15:29, #HEL)",
+		"L24(2024-07-23T15:29Z, Helsinki)":     "L24(2024-07-23T15:29Z, Helsinki)",
+		"at L24(2024-07-23T15:29Z, #HEL) #HEL": "at 18:29 (+03:00) Helsinki Vantaa Airport",
+	}
+	for input, expected := range tests {
+		if actual := p.Line(input); actual != expected {
+			t.Errorf("%s: expected '%s', got '%s'", input, expected, actual)
+		}
+	}
+
+	spec := itinerary.New(testLookup(), itinerary.Options{})
+	if expected, actual := "L24(2024-07-23T15:29Z, Helsinki Vantaa Airport)", spec.Line("L24(2024-07-23T15:29Z, #HEL)"); actual != expected {
+		t.Errorf("Expected '%s' without extended tokens, got '%s'", expected, actual)
+	}
+}
+
+// TestLocalTimeCheck validates the problems check reports for local times.
+func TestLocalTimeCheck(t *testing.T) {
+	p := itinerary.New(testLookup(), itinerary.Options{Extended: true})
+
+	problems, err := p.Check(strings.NewReader("L24(2024-07-23T15:29Z, #AHJ) L24(2024-07-23T15:29Z, #XXX) L12(bad, #HEL)"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []string{
+		"1:1: can't convert L24(2024-07-23T15:29Z, #AHJ): no time zone of #AHJ in the lookup",
+		"1:30: can't convert L24(2024-07-23T15:29Z, #XXX): unresolved airport code #XXX",
+		"1:59: can't convert L12(bad, #HEL): malformed time bad",
+	}
+	if len(problems) != len(expected) {
+		t.Fatalf("Expected %d problems, got %v", len(expected), problems)
+	}
+	for i, e := range expected {
+		if problems[i].String() != e {
+			t.Errorf("Expected '%s', got '%s'", e, problems[i])
+		}
+	}
+}
+
+// TestLocalTimeLookup validates that the time zone comes from the optional
+// tz column, and that L24 is only converted in extended mode.
+func TestLocalTimeLookup(t *testing.T) {
+	const lookup = "name,iso_country,municipality,icao_code,iata_code,coordinates,tz\n" +
+		"Helsinki Vantaa Airport,FI,Helsinki,EFHK,HEL,\"24.963301, 60.3172\",Europe/Helsinki\n"
+	const input = "L24(2024-07-23T15:29Z, #HEL)"
+
+	expected := "L24(2024-07-23T15:29Z, Helsinki Vantaa Airport)"
+	if mode == "extended" {
+		expected = "18:29 (+03:00)"
+	}
+
+	if err := withMockFiles(t.TempDir(), input, lookup, func(inputFile, outputFile, lookupFile *os.File) {
+		run(t, inputFile.Name(), outputFile.Name(), lookupFile.Name())
+		data, err := os.ReadFile(outputFile.Name())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if actual := strings.TrimSpace(string(data)); actual != expected {
+			t.Errorf("Expected '%s', got '%s'", expected, actual)
+		}
+	}); err != nil {
+		t.Fatal(err)
+	}
+}
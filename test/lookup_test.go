@@ -1,7 +1,133 @@
 package test
 
-import "testing"
+import (
+	"errors"
+	"os"
+	"strings"
+	"testing"
+
+	"anyhol/itinerary"
+)
 
 func TestLookupBasic(t *testing.T) {
 
 }
+
+// TestLookupQuotedFields validates that lookup rows are parsed as CSV records,
+// so quoted fields may contain commas, escaped quotes and newlines.
+func TestLookupQuotedFields(t *testing.T) {
+	const lookup = "\uFEFF" + lookupHeader + `
+"Washington, D.C. Airport",US,"Washington, D.C.",KDCA,DCA,"-77.037697, 38.8521"
+"The ""Quoted"" Airport",US,Quoted,KQQQ,QQQ,"1.0, 2.0"
+"Two
+Lines Airport",US,Lines,KLLL,LLL,"1.0, 2.0"` + lookupBasicBody
+
+	cases := [][]string{
+		{"comma", "#DCA ##KDCA", "Washington, D.C. Airport Washington, D.C. Airport"},
+		{"escaped quotes", "#QQQ", `The "Quoted" Airport`},
+		{"newline", "##KLLL", "Two\nLines Airport"},
+		{"after multiline record", "#HIR", "Honiara International Airport"},
+	}
+
+	for _, c := range cases {
+		name, input, expected := c[0], c[1], c[2]
+		t.Run(name, func(t *testing.T) {
+			runWithMockFiles(t, input, lookup, expected, false, 5)
+		})
+	}
+}
+
+// TestLookupColumnOrder validates that lookup columns are found by their
+// header names and not by their positions.
+func TestLookupColumnOrder(t *testing.T) {
+	const lookup = `iata_code,coordinates,municipality,name,icao_code,iso_country
+HIR,"160.05499267578, -9.4280004501343",Honiara,Honiara International Airport,AGGH,SB`
+
+	runWithMockFiles(t, "#HIR ##AGGH", lookup, "Honiara International Airport Honiara International Airport", false, 5)
+}
+
+// TestLookupDuplicatedColumn validates that a lookup with a required column
+// given twice is rejected.
+func TestLookupDuplicatedColumn(t *testing.T) {
+	if err := withMockFiles(t.TempDir(), "#HIR", "name,"+basicLookup, func(inputFile, outputFile, lookupFile *os.File) {
+		runTestCaseOutputAffected(t, []caseFile{
+			{"input", inputFile.Name(), false, false, false, inputFile},
+			{"output", outputFile.Name(), true, true, true, outputFile},
+			{"lookup", lookupFile.Name(), false, false, false, lookupFile},
+		}...)
+	}); err != nil {
+		t.Fatal("Unexpected error: ", err)
+	}
+}
+
+// TestLookupBackend validates that the prettifier works with any Lookup and
+// that loaded airports keep every lookup column.
+func TestLookupBackend(t *testing.T) {
+	lookup := itinerary.NewMapLookup(&itinerary.Airport{
+		Name:         "Helsinki Airport",
+		Municipality: "Helsinki",
+		IATA:         "HEL",
+		ICAO:         "EFHK",
+	})
+
+	p := itinerary.New(lookup, itinerary.Options{})
+	if actual, expected := p.Line("#HEL *##EFHK #XXX"), "Helsinki Airport Helsinki #XXX"; actual != expected {
+		t.Fatalf("Expected '%s', got '%s'", expected, actual)
+	}
+
+	loaded, err := itinerary.LoadLookup("test/input/lookup.csv", itinerary.DefaultColumns())
+	if err != nil {
+		t.Fatal(err)
+	}
+	airport, ok := loaded.ByIATA("HIR")
+	if !ok {
+		t.Fatal("HIR not found")
+	}
+	if airport.Country != "SB" || airport.ICAO != "AGGH" || airport.Latitude != -9.4280004501343 || airport.Longitude != 160.05499267578 {
+		t.Fatalf("Unexpected airport %+v", airport)
+	}
+}
+
+// TestLookupErrors validates that lookup failures can be told apart with
+// errors.Is and errors.As, and that they carry the position of the problem.
+func TestLookupErrors(t *testing.T) {
+	if _, err := itinerary.LoadLookup(getUniqueName(), itinerary.DefaultColumns()); !errors.Is(err, itinerary.ErrLookupNotFound) {
+		t.Errorf("Expected ErrLookupNotFound, got %v", err)
+	}
+
+	cases := []struct {
+		name   string
+		lookup string
+		row    int
+		column int
+		field  string
+	}{
+		{"MissingColumn", "name,iso_country,municipality,icao_code,iata_code" + lookupBasicBody, 1, 0, itinerary.FieldCoordinates},
+		{"DuplicatedColumn", "name," + basicLookup, 1, 2, itinerary.FieldName},
+		{"BlankField", basicLookup + "\nSydney Airport,AU,,YSSY,SYD,\"151.177, -33.9461\"", 7, 3, itinerary.FieldMunicipality},
+		{"Coordinates", basicLookup + "\nSydney Airport,AU,Sydney,YSSY,SYD,151.177", 7, 6, itinerary.FieldCoordinates},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if err := withTempFile(t.TempDir(), func(file *os.File) {
+				writeAndCloseFile(t, file, c.lookup)
+
+				_, err := itinerary.LoadLookup(file.Name(), itinerary.DefaultColumns())
+
+				var malformed *itinerary.MalformedLookupError
+				if !errors.As(err, &malformed) {
+					t.Fatalf("Expected MalformedLookupError, got %v", err)
+				}
+				if malformed.File != file.Name() || malformed.Row != c.row || malformed.Column != c.column || malformed.Field != c.field {
+					t.Fatalf("Unexpected error position %+v", malformed)
+				}
+				if strings.Contains(err.Error(), "\033") {
+					t.Fatalf("Error contains escape codes: %q", err.Error())
+				}
+			}); err != nil {
+				t.Fatal("Unexpected error: ", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,19 @@
+package test
+
+import (
+	"fmt"
+	"os"
+	"testing"
+)
+
+// TestMain runs the whole suite once for every mode of the program.
+func TestMain(m *testing.M) {
+	code := 0
+	for _, mode = range modes {
+		fmt.Printf("=== MODE %s\n", mode)
+		if c := m.Run(); c != 0 {
+			code = c
+		}
+	}
+	os.Exit(code)
+}
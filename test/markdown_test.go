@@ -0,0 +1,101 @@
+package test
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"anyhol/itinerary"
+)
+
+// TestMarkdownRenderer validates that Markdown characters are escaped, so
+// unresolved codes never become headings, and that paragraphs are kept.
+func TestMarkdownRenderer(t *testing.T) {
+	p := itinerary.New(testLookup(), itinerary.Options{Renderer: itinerary.MarkdownRenderer{}})
+
+	const input = "#XXX *not* [a](link)\n- from #HIR\n\n\n1. at T24(2024-07-23T15:29-11:00)\n==="
+	const expected = "\\#XXX \\*not\\* \\[a\\](link)\\\n" +
+		"\\- from **Honiara International Airport**\n\n" +
+		"1\\. at 15:29 (-11:00)\\\n" +
+		"\\===\n"
+
+	if actual := p.Document(input); actual != expected {
+		t.Errorf("Expected %q, got %q", expected, actual)
+	}
+}
+
+// TestMarkdownCodes validates the "name (IATA)" form of airports.
+func TestMarkdownCodes(t *testing.T) {
+	p := itinerary.New(testLookup(), itinerary.Options{Renderer: itinerary.MarkdownRenderer{Codes: true}})
+
+	const expected = "From Honiara International Airport (HIR) to Aba"
+	if actual := p.Line("From ##AGGH to *#AHJ"); actual != expected {
+		t.Errorf("Expected %q, got %q", expected, actual)
+	}
+}
+
+// TestMarkdownIndent validates that indentation at the start of a line
+// never makes a code block.
+func TestMarkdownIndent(t *testing.T) {
+	p := itinerary.New(testLookup(), itinerary.Options{Renderer: itinerary.MarkdownRenderer{}})
+
+	const input = "From #HIR\n\n\tcode?\n\n\t- to #XXX"
+	const expected = "From **Honiara International Airport**\n\n" +
+		"&#9;code?\n\n" +
+		"&#9;- to \\#XXX\n"
+
+	if actual := p.Document(input); actual != expected {
+		t.Errorf("Expected %q, got %q", expected, actual)
+	}
+
+	const text = "    code?" // spaces are only kept when text is rendered directly
+	if actual := (itinerary.MarkdownRenderer{}).Text(text); actual != "&#32;   code?" {
+		t.Errorf("Expected %q, got %q", "&#32;   code?", actual)
+	}
+}
+
+// TestMarkdownCodesICAO validates that airports without an IATA code are
+// written with their ICAO code.
+func TestMarkdownCodesICAO(t *testing.T) {
+	lookup := itinerary.NewMapLookup(&itinerary.Airport{Name: "Ahelsbury Field", ICAO: "EGZZ"})
+	p := itinerary.New(lookup, itinerary.Options{Renderer: itinerary.MarkdownRenderer{Codes: true}})
+
+	const expected = "From Ahelsbury Field (EGZZ)"
+	if actual := p.Line("From ##EGZZ"); actual != expected {
+		t.Errorf("Expected %q, got %q", expected, actual)
+	}
+}
+
+// TestMarkdownAmpersand validates that text that reads as an HTML entity
+// stays text.
+func TestMarkdownAmpersand(t *testing.T) {
+	p := itinerary.New(testLookup(), itinerary.Options{Renderer: itinerary.MarkdownRenderer{}})
+
+	const expected = "Bed \\&amp; breakfast \\&\\#9; near **Honiara International Airport**"
+	if actual := p.Line("Bed &amp; breakfast &#9; near #HIR"); actual != expected {
+		t.Errorf("Expected %q, got %q", expected, actual)
+	}
+}
+
+// TestFormatMarkdownCodes validates that -markdown-codes writes airports as
+// "name (IATA)" and needs -format markdown.
+func TestFormatMarkdownCodes(t *testing.T) {
+	if err := withMockFiles(t.TempDir(), "From #HIR", basicLookup, func(inputFile, outputFile, lookupFile *os.File) {
+		run(t, "-format", "markdown", "-markdown-codes", inputFile.Name(), outputFile.Name(), lookupFile.Name())
+
+		data, err := os.ReadFile(outputFile.Name())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if expected, actual := "From Honiara International Airport (HIR)", strings.TrimSpace(string(data)); actual != expected {
+			t.Errorf("Expected '%s', got '%s'", expected, actual)
+		}
+
+		output, err := runUnhandled(t, "-markdown-codes", inputFile.Name(), outputFile.Name(), lookupFile.Name())
+		if err == nil || !strings.Contains(output, "-markdown-codes needs -format markdown") {
+			t.Errorf("Expected -markdown-codes to need -format markdown, got:\n%s", output)
+		}
+	}); err != nil {
+		t.Fatal(err)
+	}
+}
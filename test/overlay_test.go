@@ -0,0 +1,97 @@
+package test
+
+import (
+	"errors"
+	"os"
+	"strings"
+	"testing"
+
+	"anyhol/itinerary"
+)
+
+// TestOverlay validates that an overlay changes airports field by field,
+// deletes and adds airports, and records where every value comes from.
+func TestOverlay(t *testing.T) {
+	lookup, err := itinerary.LoadLookup("test/input/lookup.csv", itinerary.DefaultColumns())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	const overlay = "icao_code,municipality,delete,name,iata_code,iso_country,coordinates\n" +
+		"AGGH,Honiara City,,,,,\n" + // only the municipality
+		",,yes,,AHJ,,\n" +
+		"EFHK,Vantaa,,Helsinki Vantaa Airport,HEL,FI,\"24.963301, 60.3172\"\n"
+	if err := lookup.ApplyOverlay(strings.NewReader(overlay), "fixes.csv", itinerary.DefaultColumns()); err != nil {
+		t.Fatal(err)
+	}
+
+	p := itinerary.New(lookup, itinerary.Options{})
+	const input = "#HIR *#HIR #AHJ *##ZUHY *#HEL"
+	if expected, actual := "Honiara International Airport Honiara City #AHJ *##ZUHY Vantaa", p.Line(input); actual != expected {
+		t.Errorf("Expected '%s', got '%s'", expected, actual)
+	}
+
+	d, err := p.Describe(strings.NewReader(input))
+	if err != nil {
+		t.Fatal(err)
+	}
+	sources := map[string]string{"#HIR": "test/input/lookup.csv", "*#HIR": "fixes.csv", "#AHJ": "", "*##ZUHY": "", "*#HEL": "fixes.csv"}
+	for _, tok := range d.Tokens {
+		if tok.Source != sources[tok.Token] {
+			t.Errorf("Expected %s from '%s', got '%s'", tok.Token, sources[tok.Token], tok.Source)
+		}
+	}
+
+	if a, _ := lookup.ByIATA("HIR"); a.Latitude != -9.4280004501343 || a.Country != "SB" {
+		t.Errorf("Blank overlay fields changed the airport: %+v", a)
+	}
+}
+
+// TestOverlayErrors validates that overlays without codes and new airports
+// without every field are rejected.
+func TestOverlayErrors(t *testing.T) {
+	cases := map[string]string{
+		"no code column": "name\nAirport\n",
+		"blank codes":    "iata_code,name\n,Airport\n",
+		"new airport":    "iata_code,name\nNEW,New Airport\n",
+		"coordinates":    "iata_code,coordinates\nHIR,north\n",
+	}
+	for name, overlay := range cases {
+		lookup := itinerary.NewMapLookup(&itinerary.Airport{Name: "Honiara International Airport", IATA: "HIR", ICAO: "AGGH"})
+		err := lookup.ApplyOverlay(strings.NewReader(overlay), "fixes.csv", itinerary.DefaultColumns())
+		var e *itinerary.MalformedLookupError
+		if !errors.As(err, &e) || e.File != "fixes.csv" {
+			t.Errorf("%s: expected MalformedLookupError of fixes.csv, got %v", name, err)
+		}
+	}
+}
+
+// TestOverlayFlag validates -lookup and -provenance.
+func TestOverlayFlag(t *testing.T) {
+	if err := withTempFiles(t.TempDir(), 4, func(files ...*os.File) {
+		input, output, lookup, overlay := files[0], files[1], files[2], files[3]
+		writeAndCloseFile(t, input, "#HIR *#HIR")
+		writeAndCloseFile(t, lookup, basicLookup)
+		writeAndCloseFile(t, overlay, "iata_code,municipality\nHIR,Honiara City\n")
+
+		stdout := run(t, "-lookup", overlay.Name(), "-provenance", input.Name(), output.Name(), lookup.Name())
+
+		data, err := os.ReadFile(output.Name())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if expected, actual := "Honiara International Airport Honiara City", strings.TrimSpace(string(data)); actual != expected {
+			t.Errorf("Expected '%s', got '%s'", expected, actual)
+		}
+		for _, expected := range []string{
+			":1:1: #HIR = Honiara International Airport (" + lookup.Name() + ")",
+			":1:6: *#HIR = Honiara City (" + overlay.Name() + ")",
+		} {
+			if !strings.Contains(stdout, input.Name()+expected) {
+				t.Errorf("'%s' not found in output:\n%s", expected, stdout)
+			}
+		}
+	}); err != nil {
+		t.Fatal(err)
+	}
+}
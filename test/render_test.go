@@ -0,0 +1,82 @@
+package test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"anyhol/itinerary"
+)
+
+// tagRenderer marks every converted value with its kind
+type tagRenderer struct{}
+
+func (tagRenderer) Text(s string) string                      { return s }
+func (tagRenderer) AirportName(a *itinerary.Airport) string   { return "<airport " + a.Name + ">" }
+func (tagRenderer) City(a *itinerary.Airport) string          { return "<city " + a.Municipality + ">" }
+func (tagRenderer) Date(t time.Time, formatted string) string { return "<date " + formatted + ">" }
+func (tagRenderer) Time(t time.Time, formatted string) string { return "<time " + formatted + ">" }
+
+func testLookup() itinerary.Lookup {
+	return itinerary.NewMapLookup(&itinerary.Airport{
+		Name:         "Honiara International Airport",
+		Country:      "SB",
+		Municipality: "Honiara",
+		ICAO:         "AGGH",
+		IATA:         "HIR",
+		Latitude:     -9.4280004501343,
+		Longitude:    160.05499267578,
+		Timezone:     "Pacific/Guadalcanal",
+	}, &itinerary.Airport{
+		Name:         "Hongyuan Airport",
+		Country:      "CN",
+		Municipality: "Aba",
+		ICAO:         "ZUHY",
+		IATA:         "AHJ",
+		Latitude:     32.53154,
+		Longitude:    102.35224,
+	}, &itinerary.Airport{
+		Name:         "Helsinki Vantaa Airport",
+		Country:      "FI",
+		Municipality: "Helsinki",
+		ICAO:         "EFHK",
+		IATA:         "HEL",
+		Latitude:     60.3172,
+		Longitude:    24.963301,
+		Timezone:     "Europe/Helsinki",
+	})
+}
+
+// TestRenderer validates that every converted value goes through the renderer.
+func TestRenderer(t *testing.T) {
+	p := itinerary.New(testLookup(), itinerary.Options{Renderer: tagRenderer{}})
+
+	const input = "#HIR *##AGGH D(2022-05-09T08:07Z) T12(2069-04-24T19:18-02:00) T24(bad) #XXX"
+	const expected = "<airport Honiara International Airport> <city Honiara> <date 09 May 2022> <time 07:18PM (-02:00)> T24(bad) #XXX"
+
+	if actual := p.Line(input); actual != expected {
+		t.Fatalf("Expected '%s', got '%s'", expected, actual)
+	}
+}
+
+// TestRenderTargets validates that one conversion gives both the plain output
+// and the coloured preview, and that no escape codes leak into plain text.
+func TestRenderTargets(t *testing.T) {
+	p := itinerary.New(testLookup(), itinerary.Options{})
+
+	const input = "From #HIR\n\n\n\nat T24(2024-07-23T15:29-11:00)\n"
+	var plain, ansi strings.Builder
+	if err := p.ConvertTo(strings.NewReader(input),
+		itinerary.Target{W: &plain, Renderer: itinerary.PlainRenderer{}},
+		itinerary.Target{W: &ansi, Renderer: itinerary.ANSIRenderer{}},
+	); err != nil {
+		t.Fatal(err)
+	}
+
+	if expected := "From Honiara International Airport\n\nat 15:29 (-11:00)\n"; plain.String() != expected {
+		t.Errorf("Expected plain %q, got %q", expected, plain.String())
+	}
+	if expected := "From \033[36mHoniara International Airport\033[0m\n\nat \033[40m\033[32m15:29 (-11:00)\033[0m\n"; ansi.String() != expected {
+		t.Errorf("Expected ANSI %q, got %q", expected, ansi.String())
+	}
+}
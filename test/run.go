@@ -14,6 +14,13 @@ const cwd = "../"
 const printStdoutWhenMocking = false
 const printCommand = false
 
+// modes holds every mode of the program, the whole suite runs once per mode.
+// The program takes its default mode from ITINERARY_MODE.
+var modes = [...]string{"spec", "extended"}
+
+// mode is the mode of the current run
+var mode string
+
 func init() {
 	err := os.Chdir(cwd)
 	if err != nil {
@@ -26,6 +33,7 @@ func runUnhandled(t *testing.T, args ...string) (string, error) {
 		t.Logf("Running command: go run . %s", strings.Join(args, " "))
 	}
 	cmd := exec.Command("go", append([]string{"run", "."}, args...)...)
+	cmd.Env = append(os.Environ(), "ITINERARY_MODE="+mode)
 
 	output, err := cmd.CombinedOutput()
 
@@ -0,0 +1,82 @@
+package test
+
+import (
+	"encoding/json"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+
+	"anyhol/itinerary"
+)
+
+// TestSearch validates that airports are found by code, name, city and
+// country, with codes and exact matches ranked first.
+func TestSearch(t *testing.T) {
+	lookup := itinerary.NewMapLookup(
+		&itinerary.Airport{Name: "Helsinki Vantaa Airport", Country: "FI", Municipality: "Helsinki", IATA: "HEL", ICAO: "EFHK"},
+		&itinerary.Airport{Name: "Helsinki Malmi Airport", Country: "FI", Municipality: "Helsinki", IATA: "HEM", ICAO: "EFHF"},
+		&itinerary.Airport{Name: "Turku Airport", Country: "FI", Municipality: "Turku", IATA: "TKU", ICAO: "EFTU"},
+		&itinerary.Airport{Name: "Honiara International Airport", Country: "SB", Municipality: "Honiara", IATA: "HIR", ICAO: "AGGH"},
+		&itinerary.Airport{Name: "Ahelsbury Field", Country: "GB", Municipality: "Ahelsbury", ICAO: "EGZZ"},
+		&itinerary.Airport{Name: "Luxor International Airport", Country: "EG", Municipality: "Luxor", IATA: "LXR", ICAO: "HELX"},
+	)
+
+	tests := []struct {
+		query  string
+		limit  int
+		tokens []string // first token of every match
+	}{
+		{"hel", 0, []string{"#HEL", "#HEM", "#LXR", "##EGZZ"}}, // code, word prefix, code prefix, inside
+		{"hel", 3, []string{"#HEL", "#HEM", "#LXR"}},
+		{"efhf", 0, []string{"#HEM"}},
+		{"Helsinki vantaa", 0, []string{"#HEL"}},
+		{"finland", 0, []string{"#HEL", "#HEM", "#TKU"}},
+		{"solomon", 0, []string{"#HIR"}},
+		{"airport", 2, []string{"#HEL", "#HEM"}},
+		{"helsinki oslo", 0, nil},
+		{"  ", 0, nil},
+	}
+	for _, test := range tests {
+		var actual []string
+		for _, m := range itinerary.Search(lookup, test.query, test.limit) {
+			actual = append(actual, m.Tokens[0])
+		}
+		if !reflect.DeepEqual(actual, test.tokens) {
+			t.Errorf("%q: expected %v, got %v", test.query, test.tokens, actual)
+		}
+	}
+
+	m := itinerary.Search(lookup, "turku", 0)
+	if len(m) != 1 || m[0].Field != itinerary.FieldMunicipality || !reflect.DeepEqual(m[0].Tokens, []string{"#TKU", "##EFTU"}) {
+		t.Errorf("Unexpected match of turku: %+v", m)
+	}
+}
+
+// TestSearchCommand validates that airports search prints a table or JSON
+// and fails when nothing matches.
+func TestSearchCommand(t *testing.T) {
+	if err := withTempFile(t.TempDir(), func(lookupFile *os.File) {
+		writeAndCloseFile(t, lookupFile, basicLookup)
+
+		output := run(t, "airports", "search", "honiara", lookupFile.Name())
+		if !strings.Contains(output, "#HIR ##AGGH") || !strings.Contains(output, "Honiara International Airport") {
+			t.Errorf("Expected Honiara in table, got:\n%s", output)
+		}
+
+		output = run(t, "airports", "search", "-json", "cn", lookupFile.Name())
+		var matches []itinerary.Match
+		if err := json.Unmarshal([]byte(output), &matches); err != nil {
+			t.Fatalf("Malformed JSON: %s\n%s", err, output)
+		}
+		if len(matches) != 2 || matches[0].Airport.IATA != "AHJ" || matches[1].Airport.IATA != "AXF" {
+			t.Errorf("Expected the airports of China, got:\n%s", output)
+		}
+
+		if output, err := runUnhandled(t, "airports", "search", "nowhere", lookupFile.Name()); err == nil {
+			t.Errorf("Expected error, got:\n%s", output)
+		}
+	}); err != nil {
+		t.Fatal("Unexpected error: ", err)
+	}
+}
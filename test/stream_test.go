@@ -0,0 +1,44 @@
+package test
+
+import (
+	"strings"
+	"testing"
+	"testing/iotest"
+
+	"anyhol/itinerary"
+)
+
+// TestLongLine validates that lines longer than the default 64 KiB token
+// limit of bufio.Scanner are converted.
+func TestLongLine(t *testing.T) {
+	long := strings.Repeat("a", 1<<20)
+	input := "#HIR " + long + " D(2022-05-09T08:07Z)\nnext"
+	expected := "Honiara International Airport " + long + " 09 May 2022\nnext"
+
+	runWithMockFiles(t, input, basicLookup, expected, false, 5)
+}
+
+// TestChunkedInput validates that the output does not depend on how the
+// input reader splits the text, blank lines are collapsed across reads.
+func TestChunkedInput(t *testing.T) {
+	lookup := itinerary.NewMapLookup(&itinerary.Airport{Name: "Honiara International Airport", IATA: "HIR", ICAO: "AGGH"})
+	p := itinerary.New(lookup, itinerary.Options{})
+
+	const input = "\n\n\nA #HIR\r\n\n\n\nB  \v\f\rC\n\n\n\n"
+	const expected = "\n\nA Honiara International Airport\n\nB\n\nC\n\n"
+
+	var whole, chunked strings.Builder
+	if err := p.Convert(strings.NewReader(input), &whole); err != nil {
+		t.Fatal(err)
+	}
+	if err := p.Convert(iotest.OneByteReader(strings.NewReader(input)), &chunked); err != nil {
+		t.Fatal(err)
+	}
+
+	if whole.String() != expected {
+		t.Errorf("Expected %q, got %q", expected, whole.String())
+	}
+	if chunked.String() != whole.String() {
+		t.Errorf("Chunked input gives %q, whole input gives %q", chunked.String(), whole.String())
+	}
+}
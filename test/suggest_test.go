@@ -0,0 +1,166 @@
+package test
+
+import (
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+
+	"anyhol/itinerary"
+)
+
+// TestSuggestions validates that unresolved codes get the closest known
+// codes, swapped letters and neighbouring keys first.
+func TestSuggestions(t *testing.T) {
+	lookup := itinerary.NewMapLookup(
+		&itinerary.Airport{Name: "Helsinki Vantaa Airport", Country: "FI", Municipality: "Helsinki", IATA: "HEL", ICAO: "EFHK"},
+		&itinerary.Airport{Name: "Helsinki Malmi Airport", Country: "FI", Municipality: "Helsinki", IATA: "HEM", ICAO: "EFHF"},
+		&itinerary.Airport{Name: "Lecce Airport", Country: "IT", Municipality: "Lecce", IATA: "LCC", ICAO: "LIBN"},
+		&itinerary.Airport{Name: "Turku Airport", Country: "FI", Municipality: "Turku", IATA: "TKU", ICAO: "EFTU"},
+		&itinerary.Airport{Name: "Heringsdorf Airport", Country: "DE", Municipality: "Heringsdorf", IATA: "HDF", ICAO: "EDAH"},
+		&itinerary.Airport{Name: "Hemavan Airport", Country: "SE", Municipality: "Hemavan", IATA: "HMV", ICAO: "ESUT"},
+		&itinerary.Airport{Name: "Lakhimpur Airport", Country: "IN", Municipality: "Lakhimpur", ICAO: "VA1G"},
+	)
+	p := itinerary.New(lookup, itinerary.Options{})
+
+	tests := []struct {
+		input       string
+		suggestions []string
+	}{
+		{"#HLE", []string{"#HEL"}},                  // swapped letters
+		{"*##EFHJ", []string{"*##EFHK", "*##EFHF"}}, // J is next to K
+		{"#HEK", []string{"#HEL", "#HEM"}},          // K is next to L and M
+		{"#TKU #HEN", []string{"#HEM", "#HEL"}},     // N is next to M
+		{"#HDF to #HEN", []string{"#HEM", "#HEL"}},
+		{"#QQQ", nil},
+		{"##VALG", nil}, // ##VA1G is never a token
+	}
+	for _, test := range tests {
+		problems, err := p.Check(strings.NewReader(test.input))
+		if err != nil {
+			t.Fatal(err)
+		}
+		var actual []string
+		for _, problem := range problems {
+			actual = append(actual, problem.Suggestions...)
+		}
+		if !reflect.DeepEqual(actual, test.suggestions) {
+			t.Errorf("%s: expected %v, got %v", test.input, test.suggestions, actual)
+		}
+	}
+
+	problems, _ := p.Check(strings.NewReader("From #HLE"))
+	if expected := "1:6: unresolved airport code #HLE, did you mean #HEL?"; len(problems) != 1 || problems[0].String() != expected {
+		t.Errorf("Expected '%s', got %v", expected, problems)
+	}
+}
+
+// TestSuggestionsRealLookup validates the ranking against the lookup of
+// the repo: swapped letters come before slips onto neighbouring keys.
+func TestSuggestionsRealLookup(t *testing.T) {
+	lookup, err := itinerary.LoadLookup("test/input/lookup.csv", itinerary.DefaultColumns())
+	if err != nil {
+		t.Fatal(err)
+	}
+	p := itinerary.New(lookup, itinerary.Options{})
+
+	tests := map[string]string{
+		"From #HLE": "1:6: unresolved airport code #HLE, did you mean #HEL, #LHE or #HLD?",
+		"To ##EFKH": "1:4: unresolved airport code ##EFKH, did you mean ##EFHK, ##EFKU or ##EFJY?",
+	}
+	for input, expected := range tests {
+		problems, err := p.Check(strings.NewReader(input))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(problems) != 1 || problems[0].String() != expected {
+			t.Errorf("%s: expected '%s', got %v", input, expected, problems)
+		}
+	}
+}
+
+// TestSuggestionsPreferLine validates that among equally close codes, the
+// ones in the country or city of the other airports of the line come first.
+func TestSuggestionsPreferLine(t *testing.T) {
+	lookup := itinerary.NewMapLookup(
+		&itinerary.Airport{Name: "A", Country: "SE", Municipality: "Aa", IATA: "ABL"},
+		&itinerary.Airport{Name: "B", Country: "FI", Municipality: "Bb", IATA: "ABO"},
+		&itinerary.Airport{Name: "C", Country: "FI", Municipality: "Cc", IATA: "ABP"},
+		&itinerary.Airport{Name: "D", Country: "FI", Municipality: "Cc", IATA: "XYZ"},
+	)
+	p := itinerary.New(lookup, itinerary.Options{})
+
+	tests := map[string][]string{
+		"#ABC":      {"#ABL", "#ABO", "#ABP"},
+		"#ABC #XYZ": {"#ABP", "#ABO", "#ABL"}, // same city, same country, elsewhere
+	}
+	for input, expected := range tests {
+		problems, err := p.Check(strings.NewReader(input))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(problems) != 1 || !reflect.DeepEqual(problems[0].Suggestions, expected) {
+			t.Errorf("%s: expected %v, got %v", input, expected, problems)
+		}
+	}
+}
+
+// TestSuggestionWarnings validates that extended mode warns about
+// unresolved codes with suggestions.
+func TestSuggestionWarnings(t *testing.T) {
+	if err := withMockFiles(t.TempDir(), "#HRI", basicLookup, func(inputFile, outputFile, lookupFile *os.File) {
+		stdout := run(t, inputFile.Name(), outputFile.Name(), lookupFile.Name())
+
+		warned := strings.Contains(stdout, "unresolved airport code #HRI, did you mean #HIR?")
+		if mode == "extended" && !warned {
+			t.Errorf("Expected a warning with suggestion, got:\n%s", stdout)
+		}
+		if mode == "spec" && warned {
+			t.Errorf("Expected no warning in spec mode, got:\n%s", stdout)
+		}
+	}); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// TestSuggestionsLargeLookup validates suggestions against a lookup of 80k
+// airports. ##QQQX is one slip away from ##AQQX, and ##DQQX is the airport
+// of #QQX on the same line.
+func TestSuggestionsLargeLookup(t *testing.T) {
+	p := itinerary.New(largeMapLookup(80000), itinerary.Options{})
+
+	problems, err := p.Check(strings.NewReader(strings.Repeat("From #QQX to ##QQQX\n", 300)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(problems) != 300 {
+		t.Fatalf("Expected 300 problems, got %d", len(problems))
+	}
+	if expected := []string{"##AQQX", "##DQQX", "##AAQX"}; !reflect.DeepEqual(problems[0].Suggestions, expected) {
+		t.Errorf("Expected %v, got %v", expected, problems[0].Suggestions)
+	}
+}
+
+// BenchmarkCheckSuggestions checks 300 lines of typos against a lookup of
+// 80k airports.
+func BenchmarkCheckSuggestions(b *testing.B) {
+	p := itinerary.New(largeMapLookup(80000), itinerary.Options{})
+	input := strings.Repeat("From #QQX to ##QQQX at T24(2024-07-23T15:29Z)\n", 300)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := p.Check(strings.NewReader(input)); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// largeMapLookup returns a lookup of n made up airports
+func largeMapLookup(n int) *itinerary.MapLookup {
+	lookup := itinerary.NewMapLookup()
+	for i := 0; i < n; i++ {
+		icao := string([]byte{byte('A' + i/17576%26), byte('A' + i/676%26), byte('A' + i/26%26), byte('A' + i%26)})
+		lookup.Add(&itinerary.Airport{Name: icao + " Airport", Country: "FI", Municipality: icao, ICAO: icao, IATA: icao[1:]})
+	}
+	return lookup
+}
@@ -0,0 +1,69 @@
+package test
+
+import (
+	"errors"
+	"os"
+	"strings"
+	"testing"
+
+	"anyhol/itinerary"
+)
+
+const structuredInput = `{"segments": [
+	{"from": "HIR", "to": "ZUHY", "departure": "2024-07-23T15:29-11:00", "arrival": "2024-07-24T18:00+08:00", "flight": "XX 123"},
+	{"from": "AHJ", "to": "XXX", "departure": "bad", "arrival": "2024-08-01T10:00Z", "flight": "XX 124"}
+]}`
+
+const structuredTemplate = `{{range .Segments}}{{.Flight}}: {{airport .From}} ({{city .From}}) -> {{airport .To}}, {{date .Departure}} {{time24 .Departure}} - {{time12 .Arrival}}
+{{end}}`
+
+// TestTemplate validates that a structured itinerary is written through a
+// template, and that the helpers leave what they can't convert as it is.
+func TestTemplate(t *testing.T) {
+	p := itinerary.New(testLookup(), itinerary.Options{})
+	tmpl, err := p.Template("test", structuredTemplate)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	const expected = "XX 123: Honiara International Airport (Honiara) -> Hongyuan Airport, 23 Jul 2024 15:29 (-11:00) - 06:00PM (+08:00)\n" +
+		"XX 124: Hongyuan Airport (Aba) -> XXX, bad bad - 10:00AM (+00:00)\n"
+
+	for _, input := range []string{structuredInput, strings.TrimSuffix(strings.TrimPrefix(structuredInput, `{"segments": `), "}")} {
+		var out strings.Builder
+		if err := p.ExecuteTemplate(tmpl, strings.NewReader(input), &out); err != nil {
+			t.Fatal(err)
+		}
+		if out.String() != expected {
+			t.Errorf("Expected %q, got %q", expected, out.String())
+		}
+	}
+
+	err = p.ExecuteTemplate(tmpl, strings.NewReader(`{"segments": [}`), &strings.Builder{})
+	if !errors.Is(err, itinerary.ErrMalformedInput) {
+		t.Errorf("Expected ErrMalformedInput, got %v", err)
+	}
+}
+
+// TestTemplateFlag validates that -template reads the input as a
+// structured itinerary.
+func TestTemplateFlag(t *testing.T) {
+	if err := withTempFiles(t.TempDir(), 4, func(files ...*os.File) {
+		input, output, lookup, tmpl := files[0], files[1], files[2], files[3]
+		writeAndCloseFile(t, input, `[{"from": "HIR", "to": "AGGH", "departure": "2024-07-23T15:29-11:00", "flight": "XX 1"}]`)
+		writeAndCloseFile(t, lookup, basicLookup)
+		writeAndCloseFile(t, tmpl, `{{range .Segments}}{{.Flight}} {{city .From}} {{time24 .Departure}}{{end}}`)
+
+		run(t, "-template", tmpl.Name(), input.Name(), output.Name(), lookup.Name())
+
+		data, err := os.ReadFile(output.Name())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if expected := "XX 1 Honiara 15:29 (-11:00)"; string(data) != expected {
+			t.Errorf("Expected %q, got %q", expected, data)
+		}
+	}); err != nil {
+		t.Fatal(err)
+	}
+}